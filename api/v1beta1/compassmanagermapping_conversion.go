@@ -0,0 +1,120 @@
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// labels written by Compass Manager on v1beta1 mappings, the source of the v1beta2 spec
+	labelKymaName        = "operator.kyma-project.io/kyma-name"
+	labelGlobalAccountID = "kyma-project.io/global-account-id"
+	labelSubaccountID    = "kyma-project.io/subaccount-id"
+
+	// SpecAnnotation stores the v1beta2 spec of a mapping served as v1beta1, so that the conversion is lossless
+	SpecAnnotation = "operator.kyma-project.io/v1beta2-spec"
+	// StatusAnnotation stores the v1beta2 status fields missing in v1beta1, so that updating a mapping served as v1beta1 doesn't clear them
+	StatusAnnotation = "operator.kyma-project.io/v1beta2-status"
+)
+
+// v1beta2Status is the part of the v1beta2 status stored in StatusAnnotation
+type v1beta2Status struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	LastError          string             `json:"lastError,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ConvertTo converts this CompassManagerMapping to the Hub version (v1beta2).
+// The spec is restored from SpecAnnotation if present, otherwise it is derived from the labels. The status fields missing in v1beta1
+// are restored from StatusAnnotation.
+func (src *CompassManagerMapping) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta2.CompassManagerMapping)
+	if !ok {
+		return errors.Errorf("unexpected conversion hub type: %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = v1beta2.CompassManagerMappingStatus{
+		Registered: src.Status.Registered,
+		Configured: src.Status.Configured,
+		State:      src.Status.State,
+	}
+
+	if raw, ok := dst.Annotations[StatusAnnotation]; ok {
+		delete(dst.Annotations, StatusAnnotation)
+		status := v1beta2Status{}
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			return errors.Wrapf(err, "failed to restore status of Compass Manager Mapping %s", src.Name)
+		}
+		dst.Status.ObservedGeneration = status.ObservedGeneration
+		dst.Status.LastError = status.LastError
+		dst.Status.Conditions = status.Conditions
+	}
+
+	if raw, ok := dst.Annotations[SpecAnnotation]; ok {
+		delete(dst.Annotations, SpecAnnotation)
+		if err := json.Unmarshal([]byte(raw), &dst.Spec); err != nil {
+			return errors.Wrapf(err, "failed to restore spec of Compass Manager Mapping %s", src.Name)
+		}
+		return nil
+	}
+
+	dst.Spec = specFromLabels(src)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta2) to this version.
+func (dst *CompassManagerMapping) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta2.CompassManagerMapping)
+	if !ok {
+		return errors.Errorf("unexpected conversion hub type: %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = CompassManagerMappingStatus{
+		Registered: src.Status.Registered,
+		Configured: src.Status.Configured,
+		State:      src.Status.State,
+	}
+
+	spec, err := json.Marshal(src.Spec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store spec of Compass Manager Mapping %s", src.Name)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = make(map[string]string)
+	}
+	dst.Annotations[SpecAnnotation] = string(spec)
+
+	status, err := json.Marshal(v1beta2Status{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		LastError:          src.Status.LastError,
+		Conditions:         src.Status.Conditions,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to store status of Compass Manager Mapping %s", src.Name)
+	}
+	dst.Annotations[StatusAnnotation] = string(status)
+
+	return nil
+}
+
+func specFromLabels(mapping *CompassManagerMapping) v1beta2.CompassManagerMappingSpec {
+	kymaName, ok := mapping.Labels[labelKymaName]
+	if !ok || kymaName == "" {
+		kymaName = mapping.Name
+	}
+
+	return v1beta2.CompassManagerMappingSpec{
+		KymaRef: v1beta2.KymaReference{
+			Name:      kymaName,
+			Namespace: mapping.Namespace,
+		},
+		GlobalAccountID: mapping.Labels[labelGlobalAccountID],
+		SubaccountID:    mapping.Labels[labelSubaccountID],
+	}
+}
@@ -0,0 +1,117 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompassManagerMappingConversion(t *testing.T) {
+	t.Run("should migrate label-based mapping to v1beta2 spec", func(t *testing.T) {
+		// given
+		src := &CompassManagerMapping{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kyma-a",
+				Namespace: "kcp-system",
+				Labels: map[string]string{
+					labelKymaName:                        "kyma-a",
+					labelGlobalAccountID:                 "global-account",
+					labelSubaccountID:                    "subaccount",
+					"kyma-project.io/compass-runtime-id": "runtime-id",
+				},
+			},
+			Status: CompassManagerMappingStatus{Registered: true, Configured: true, State: "Ready"},
+		}
+		dst := &v1beta2.CompassManagerMapping{}
+
+		// when
+		err := src.ConvertTo(dst)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, v1beta2.KymaReference{Name: "kyma-a", Namespace: "kcp-system"}, dst.Spec.KymaRef)
+		assert.Equal(t, "global-account", dst.Spec.GlobalAccountID)
+		assert.Equal(t, "subaccount", dst.Spec.SubaccountID)
+		assert.Equal(t, "runtime-id", dst.Labels["kyma-project.io/compass-runtime-id"])
+		assert.Equal(t, v1beta2.CompassManagerMappingStatus{Registered: true, Configured: true, State: "Ready"}, dst.Status)
+	})
+
+	t.Run("should fall back to the mapping name when the Kyma name label is missing", func(t *testing.T) {
+		// given
+		src := &CompassManagerMapping{
+			ObjectMeta: metav1.ObjectMeta{Name: "kyma-b", Namespace: "kcp-system"},
+		}
+		dst := &v1beta2.CompassManagerMapping{}
+
+		// when
+		err := src.ConvertTo(dst)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "kyma-b", dst.Spec.KymaRef.Name)
+	})
+
+	t.Run("should keep v1beta2 spec on round trip", func(t *testing.T) {
+		// given
+		hub := &v1beta2.CompassManagerMapping{
+			ObjectMeta: metav1.ObjectMeta{Name: "kyma-c", Namespace: "kcp-system"},
+			Spec: v1beta2.CompassManagerMappingSpec{
+				KymaRef:         v1beta2.KymaReference{Name: "kyma-c", Namespace: "kcp-system"},
+				GlobalAccountID: "global-account",
+				RuntimeName:     "my-runtime",
+				Labels:          map[string]string{"region": "eu"},
+				AgentConfiguration: v1beta2.AgentConfiguration{
+					SecretName:      "agent-config",
+					SecretNamespace: "compass-system",
+				},
+			},
+		}
+		spoke := &CompassManagerMapping{}
+		restored := &v1beta2.CompassManagerMapping{}
+
+		// when
+		require.NoError(t, spoke.ConvertFrom(hub))
+		require.NoError(t, spoke.ConvertTo(restored))
+
+		// then
+		assert.Contains(t, spoke.Annotations, SpecAnnotation)
+		assert.NotContains(t, restored.Annotations, SpecAnnotation)
+		assert.Equal(t, hub.Spec, restored.Spec)
+	})
+
+	t.Run("should keep v1beta2 status on round trip", func(t *testing.T) {
+		// given
+		hub := &v1beta2.CompassManagerMapping{
+			ObjectMeta: metav1.ObjectMeta{Name: "kyma-d", Namespace: "kcp-system"},
+			Status: v1beta2.CompassManagerMappingStatus{
+				Registered:         true,
+				State:              "Failed",
+				ObservedGeneration: 3,
+				LastError:          "tenant not found",
+				Conditions: []metav1.Condition{{
+					Type:               v1beta2.ConditionTypeAgentConfigured,
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 3,
+					LastTransitionTime: metav1.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+					Reason:             "err_director_tenant_not_found",
+					Message:            "tenant not found",
+				}},
+			},
+		}
+		spoke := &CompassManagerMapping{}
+		restored := &v1beta2.CompassManagerMapping{}
+
+		// when
+		require.NoError(t, spoke.ConvertFrom(hub))
+		require.NoError(t, spoke.ConvertTo(restored))
+
+		// then
+		assert.Contains(t, spoke.Annotations, StatusAnnotation)
+		assert.NotContains(t, restored.Annotations, StatusAnnotation)
+		assert.Equal(t, hub.Status, restored.Status)
+	})
+}
@@ -16,6 +16,7 @@ type CompassManagerMappingStatus struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:deprecatedversion:warning="operator.kyma-project.io/v1beta1 CompassManagerMapping is deprecated, use operator.kyma-project.io/v1beta2"
 
 // CompassManagerMapping is the Schema for the compassmanagermappings API
 type CompassManagerMapping struct {
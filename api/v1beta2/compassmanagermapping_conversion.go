@@ -0,0 +1,4 @@
+package v1beta2
+
+// Hub marks this type as a conversion hub.
+func (*CompassManagerMapping) Hub() {}
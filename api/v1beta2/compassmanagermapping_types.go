@@ -0,0 +1,112 @@
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KymaReference points to the Kyma resource the CompassManagerMapping belongs to
+type KymaReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// AgentConfiguration defines where the Compass Runtime Agent configuration is stored in the Runtime
+type AgentConfiguration struct {
+	// SecretName is the name of the secret read by the Compass Runtime Agent. Defaults to `compass-agent-configuration`.
+	SecretName string `json:"secretName,omitempty"`
+	// SecretNamespace is the namespace of the secret read by the Compass Runtime Agent. Defaults to `kyma-system`.
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+}
+
+// ModuleRemovalPolicy defines what happens to the Runtime registration when the application-connector module is removed from Kyma
+// +kubebuilder:validation:Enum=Deregister;Retain
+type ModuleRemovalPolicy string
+
+const (
+	// ModuleRemovalPolicyDeregister deregisters the Runtime from Compass, removes the Compass Runtime Agent configuration and deletes the mapping
+	ModuleRemovalPolicyDeregister ModuleRemovalPolicy = "Deregister"
+	// ModuleRemovalPolicyRetain keeps the Runtime registered in Compass and the Compass Runtime Agent configured
+	ModuleRemovalPolicyRetain ModuleRemovalPolicy = "Retain"
+)
+
+// CompassManagerMappingSpec defines the desired state of CompassManagerMapping
+type CompassManagerMappingSpec struct {
+	// KymaRef is the Kyma resource registered in Compass
+	KymaRef KymaReference `json:"kymaRef"`
+	// GlobalAccountID is the Global Account (Compass tenant) the Runtime is registered in
+	GlobalAccountID string `json:"globalAccountID,omitempty"`
+	// SubaccountID is the Subaccount the Kyma runtime belongs to
+	SubaccountID string `json:"subaccountID,omitempty"`
+	// RuntimeName is the name of the Runtime in Compass. When empty, the name is generated from the shoot name.
+	RuntimeName string `json:"runtimeName,omitempty"`
+	// Labels are additional labels set on the Runtime in Compass. They take precedence over labels derived from the Kyma resource.
+	Labels map[string]string `json:"labels,omitempty"`
+	// AgentConfiguration defines how the Compass Runtime Agent is configured in the Runtime
+	AgentConfiguration AgentConfiguration `json:"agentConfiguration,omitempty"`
+	// ModuleRemovalPolicy defines what happens when the application-connector module is removed from Kyma. Defaults to `Deregister`.
+	// +optional
+	ModuleRemovalPolicy ModuleRemovalPolicy `json:"moduleRemovalPolicy,omitempty"`
+}
+
+const (
+	// ConditionTypeRuntimeRegistered reports whether the Runtime is registered in Compass
+	ConditionTypeRuntimeRegistered = "RuntimeRegistered"
+	// ConditionTypeAgentConfigured reports whether the Compass Runtime Agent configuration exists in the Runtime
+	ConditionTypeAgentConfigured = "AgentConfigured"
+	// ConditionTypeKubeconfigAvailable reports whether the kubeconfig of the Runtime is present in the Control Plane
+	ConditionTypeKubeconfigAvailable = "KubeconfigAvailable"
+	// ConditionTypeDirectorReachable reports whether the last call to the Compass Director succeeded
+	ConditionTypeDirectorReachable = "DirectorReachable"
+	// ConditionTypeStalled reports that Compass Manager stopped retrying the failed operation, until the mapping is updated
+	ConditionTypeStalled = "Stalled"
+	// ConditionTypeMigrated reports the progress of the migration of the Runtime to another Compass backend or Global Account
+	ConditionTypeMigrated = "Migrated"
+)
+
+// CompassManagerMappingStatus defines the observed state of CompassManagerMapping
+type CompassManagerMappingStatus struct {
+	Registered bool   `json:"registered"`
+	Configured bool   `json:"configured"`
+	State      string `json:"state,omitempty"`
+
+	// ObservedGeneration is the generation of the mapping the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastError is the message of the last failed operation. It is cleared once the mapping is Ready.
+	LastError string `json:"lastError,omitempty"`
+
+	// Conditions describe the state of the registration, the agent configuration, and the dependencies
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Kyma",type=string,JSONPath=`.spec.kymaRef.name`
+//+kubebuilder:printcolumn:name="Global Account",type=string,JSONPath=`.spec.globalAccountID`
+//+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CompassManagerMapping is the Schema for the compassmanagermappings API
+type CompassManagerMapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status CompassManagerMappingStatus `json:"status,omitempty"`
+	Spec   CompassManagerMappingSpec   `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CompassManagerMappingList contains a list of CompassManagerMapping
+type CompassManagerMappingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CompassManagerMapping `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CompassManagerMapping{}, &CompassManagerMappingList{})
+}
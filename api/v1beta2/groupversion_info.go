@@ -0,0 +1,20 @@
+// Package v1beta2 contains API Schema definitions for the operator v1beta2 API group
+// +kubebuilder:object:generate=true
+// +groupName=operator.kyma-project.io
+package v1beta2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "operator.kyma-project.io", Version: "v1beta2"} //nolint:gochecknoglobals
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion} //nolint:gochecknoglobals
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme //nolint:gochecknoglobals
+)
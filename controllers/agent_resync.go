@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/controllers/metrics"
+	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// resyncConcurrency is the number of Runtimes whose Compass Runtime Agent configuration is verified in parallel
+	resyncConcurrency = 10
+	// resyncMappingTimeout limits the verification of a single Runtime, so that an unreachable one doesn't block the resync of the others
+	resyncMappingTimeout = 2 * time.Minute
+
+	EventReasonAgentConfigurationDrift       = "AgentConfigurationDrift"
+	EventReasonAgentConfigurationRestored    = "AgentConfigurationRestored"
+	EventReasonAgentConfigurationRestoreFail = "AgentConfigurationRestoreFailed"
+)
+
+// AgentConfigurationResyncer periodically verifies the Compass Runtime Agent secret in Runtimes with Ready mappings,
+// and configures the Compass Runtime Agent again when the secret was removed or modified
+type AgentConfigurationResyncer struct {
+	Log          *log.Logger
+	Configurator Configurator
+	cluster      *ControlPlaneInterface
+	recorder     record.EventRecorder
+	metrics      metrics.Metrics
+	resyncPeriod time.Duration
+}
+
+func NewAgentConfigurationResyncer(
+	mgr manager.Manager,
+	log *log.Logger,
+	c Configurator,
+	resyncPeriod time.Duration,
+	dryRun bool,
+	metrics metrics.Metrics,
+) *AgentConfigurationResyncer {
+	return &AgentConfigurationResyncer{
+		Log:          log,
+		Configurator: c,
+		cluster:      NewControlPlaneInterface(mgr.GetClient(), log, dryRun),
+		recorder:     mgr.GetEventRecorderFor(ManagedBy),
+		metrics:      metrics,
+		resyncPeriod: resyncPeriod,
+	}
+}
+
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (r *AgentConfigurationResyncer) logger(ctx context.Context) *log.Entry {
+	return logging.FromContextOr(ctx, r.Log)
+}
+
+// Start runs the resync loop until the context is cancelled. It implements manager.Runnable.
+func (r *AgentConfigurationResyncer) Start(ctx context.Context) error {
+	r.logger(ctx).Infof("Starting Compass Runtime Agent configuration resync every %s", r.resyncPeriod)
+	wait.UntilWithContext(ctx, r.resync, r.resyncPeriod)
+	return nil
+}
+
+func (r *AgentConfigurationResyncer) resync(ctx context.Context) {
+	mappings := &v1beta2.CompassManagerMappingList{}
+	if err := r.cluster.kubectl.List(ctx, mappings); err != nil {
+		r.logger(ctx).Warnf("Failed to list Compass Manager Mappings for Compass Runtime Agent configuration resync: %v", err)
+		return
+	}
+
+	group := errgroup.Group{}
+	group.SetLimit(resyncConcurrency)
+	for i := range mappings.Items {
+		if ctx.Err() != nil {
+			break
+		}
+		group.Go(func() error {
+			r.verifyMapping(ctx, mappings.Items[i])
+			return nil
+		})
+	}
+	_ = group.Wait()
+}
+
+func (r *AgentConfigurationResyncer) verifyMapping(ctx context.Context, mapping v1beta2.CompassManagerMapping) {
+	if !isReadyForResync(mapping) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resyncMappingTimeout)
+	defer cancel()
+
+	compassRuntimeID := mapping.Labels[LabelCompassID]
+	kymaName := mappingKymaName(mapping)
+	// mappings without the recorded backend belong to the default one
+	ctx = director.ContextWithBackend(ctx, mapping.Labels[LabelDirectorBackend])
+	globalAccount := mapping.Spec.GlobalAccountID
+	if globalAccount == "" {
+		globalAccount = mapping.Labels[LabelGlobalAccountID]
+	}
+	ctx = logging.IntoContext(ctx, r.Log.WithFields(log.Fields{
+		logging.FieldKyma:             kymaName.Name,
+		logging.FieldGlobalAccount:    globalAccount,
+		logging.FieldCompassRuntimeID: compassRuntimeID,
+	}))
+
+	kubeconfig, err := r.cluster.GetKubeconfig(ctx, kymaName)
+	if err != nil || len(kubeconfig) == 0 {
+		r.logger(ctx).Infof("Kubeconfig for Kyma resource %s not available, skipping Compass Runtime Agent configuration resync", kymaName.Name)
+		return
+	}
+
+	drift, err := r.Configurator.VerifyCompassRuntimeAgent(ctx, kubeconfig, compassRuntimeID, globalAccount, mapping.Spec.AgentConfiguration)
+	if err != nil {
+		r.logger(ctx).Warnf("Failed to verify Compass Runtime Agent configuration for Kyma resource %s: %v", kymaName.Name, err)
+		return
+	}
+	if len(drift) == 0 {
+		return
+	}
+
+	driftDescription := strings.Join(drift, ", ")
+	r.logger(ctx).Infof("Compass Runtime Agent configuration for Kyma resource %s drifted (%s), configuring again", kymaName.Name, driftDescription)
+	r.recorder.Eventf(&mapping, corev1.EventTypeWarning, EventReasonAgentConfigurationDrift, "Compass Runtime Agent configuration drifted: %s", driftDescription)
+
+	err = r.Configurator.ConfigureCompassRuntimeAgent(ctx, kubeconfig, compassRuntimeID, globalAccount, mapping.Spec.AgentConfiguration)
+	if err != nil {
+		r.logger(ctx).Errorf("Failed to restore Compass Runtime Agent configuration for Kyma resource %s: %v", kymaName.Name, err)
+		r.recorder.Eventf(&mapping, corev1.EventTypeWarning, EventReasonAgentConfigurationRestoreFail, "Failed to restore Compass Runtime Agent configuration: %v", err)
+		if condErr := r.cluster.SetCompassMappingConditions(ctx, kymaName, failureConditions(v1beta2.ConditionTypeAgentConfigured, err)...); condErr != nil {
+			r.logger(ctx).Warnf("Failed to set %s condition for Kyma resource %s: %v", v1beta2.ConditionTypeAgentConfigured, kymaName.Name, condErr)
+		}
+		return
+	}
+
+	kymaCR, _ := r.cluster.GetKyma(ctx, kymaName)
+	r.metrics.IncDriftCorrected(metricsRuntime(kymaName.Name, kymaCR.Labels, mapping))
+	r.recorder.Eventf(&mapping, corev1.EventTypeNormal, EventReasonAgentConfigurationRestored, "Compass Runtime Agent configuration restored for Runtime %s", compassRuntimeID)
+	r.logger(ctx).Infof("Compass Runtime Agent configuration for Kyma resource %s restored", kymaName.Name)
+
+	condErr := r.cluster.SetCompassMappingConditions(ctx, kymaName,
+		s.ConditionTrue(v1beta2.ConditionTypeAgentConfigured, s.ReasonConfigured, "Compass Runtime Agent configured for Runtime "+compassRuntimeID),
+		s.ConditionTrue(v1beta2.ConditionTypeDirectorReachable, s.ReasonDirectorResponded, ""))
+	if condErr != nil {
+		r.logger(ctx).Warnf("Failed to set %s condition for Kyma resource %s: %v", v1beta2.ConditionTypeAgentConfigured, kymaName.Name, condErr)
+	}
+}
+
+// isReadyForResync returns true for mappings of registered and configured Runtimes that are not being deleted or migrated
+func isReadyForResync(mapping v1beta2.CompassManagerMapping) bool {
+	return mapping.Status.State == s.ReadyState && mapping.Labels[LabelCompassID] != "" && mapping.DeletionTimestamp == nil && !isMigrationRequested(mapping)
+}
+
+// mappingKymaName returns the name of the Kyma resource the mapping was created for
+func mappingKymaName(mapping v1beta2.CompassManagerMapping) types.NamespacedName {
+	kymaName := types.NamespacedName{Name: mapping.Labels[LabelKymaName], Namespace: mapping.Namespace}
+	if kymaName.Name == "" {
+		kymaName.Name = mapping.Name
+	}
+	return kymaName
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const backoffJitterFactor = 0.2
+
+// FailureBackoff tracks the consecutive failures of the reconciliation of each Kyma resource,
+// and computes the exponentially growing delay of the next attempt
+type FailureBackoff struct {
+	mu          sync.Mutex
+	failures    map[types.NamespacedName]int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	maxAttempts int
+}
+
+// NewFailureBackoff creates FailureBackoff. When maxAttempts is not positive, failed operations are retried indefinitely.
+func NewFailureBackoff(baseDelay, maxDelay time.Duration, maxAttempts int) *FailureBackoff {
+	return &FailureBackoff{
+		failures:    make(map[types.NamespacedName]int),
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Next records the failure of the reconciliation of the Kyma resource, and returns the delay of the next attempt.
+// It returns false when the operation failed maxAttempts times in a row, and should not be retried anymore.
+func (b *FailureBackoff) Next(name types.NamespacedName) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures[name]++
+	failures := b.failures[name]
+	if b.maxAttempts > 0 && failures >= b.maxAttempts {
+		return 0, false
+	}
+
+	delay := b.baseDelay
+	for i := 1; i < failures && delay < b.maxDelay; i++ {
+		delay *= 2
+	}
+	delay = wait.Jitter(delay, backoffJitterFactor)
+	if delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+	return delay, true
+}
+
+// DelayAfter returns the delay of the next check of the operation which is pending for the elapsed time, without counting it as a failure.
+// The delay grows exponentially as the one returned by Next, up to the longest delay between the attempts.
+func (b *FailureBackoff) DelayAfter(elapsed time.Duration) time.Duration {
+	delay := wait.Jitter(max(elapsed, b.baseDelay), backoffJitterFactor)
+	return min(delay, b.maxDelay)
+}
+
+// Failures returns the number of consecutive failures of the reconciliation of the Kyma resource
+func (b *FailureBackoff) Failures(name types.NamespacedName) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures[name]
+}
+
+// Forget resets the failure count of the Kyma resource, after the operation succeeded or was abandoned
+func (b *FailureBackoff) Forget(name types.NamespacedName) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, name)
+}
+
+// MaxDelay returns the longest delay between the attempts
+func (b *FailureBackoff) MaxDelay() time.Duration {
+	return b.maxDelay
+}
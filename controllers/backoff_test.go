@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestFailureBackoff(t *testing.T) {
+	kymaName := types.NamespacedName{Name: "kyma", Namespace: "kcp-system"}
+
+	t.Run("should grow delay exponentially up to the maximum", func(t *testing.T) {
+		backoff := NewFailureBackoff(time.Second, 5*time.Second, 0)
+
+		for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			delay, retry := backoff.Next(kymaName)
+
+			require.True(t, retry)
+			assert.GreaterOrEqual(t, delay, expected)
+			assert.LessOrEqual(t, delay, expected+time.Duration(float64(expected)*backoffJitterFactor))
+		}
+
+		delay, retry := backoff.Next(kymaName)
+		require.True(t, retry)
+		assert.Equal(t, 5*time.Second, delay)
+	})
+
+	t.Run("should stop retrying after max attempts", func(t *testing.T) {
+		backoff := NewFailureBackoff(time.Second, time.Minute, 2)
+
+		_, retry := backoff.Next(kymaName)
+		assert.True(t, retry)
+		_, retry = backoff.Next(kymaName)
+		assert.False(t, retry)
+	})
+
+	t.Run("should track Kyma resources separately and start over when forgotten", func(t *testing.T) {
+		backoff := NewFailureBackoff(time.Second, time.Minute, 0)
+		other := types.NamespacedName{Name: "other", Namespace: "kcp-system"}
+
+		backoff.Next(kymaName)
+		backoff.Next(kymaName)
+		backoff.Next(other)
+
+		assert.Equal(t, 2, backoff.Failures(kymaName))
+		assert.Equal(t, 1, backoff.Failures(other))
+
+		backoff.Forget(kymaName)
+		assert.Equal(t, 0, backoff.Failures(kymaName))
+	})
+	t.Run("should delay the check of the pending operation by the elapsed time, without counting it as a failure", func(t *testing.T) {
+		backoff := NewFailureBackoff(time.Second, time.Minute, 2)
+
+		for elapsed, expected := range map[time.Duration]time.Duration{0: time.Second, 10 * time.Second: 10 * time.Second, time.Hour: time.Minute} {
+			delay := backoff.DelayAfter(elapsed)
+
+			assert.GreaterOrEqual(t, delay, expected)
+			assert.LessOrEqual(t, delay, expected+time.Duration(float64(expected)*backoffJitterFactor))
+		}
+		assert.Equal(t, 0, backoff.Failures(kymaName))
+	})
+}
@@ -6,28 +6,45 @@ import (
 	"slices"
 	"time"
 
-	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 	"github.com/kyma-project/compass-manager/controllers/metrics"
 	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	ManagedBy = "compass-manager"
 
 	Finalizer             = "kyma-project.io/cm-protection"
+	KymaFinalizer         = "kyma-project.io/compass-manager"
 	LabelBrokerInstanceID = "kyma-project.io/instance-id"
 	LabelBrokerPlanID     = "kyma-project.io/broker-plan-id"
 	LabelBrokerPlanName   = "kyma-project.io/broker-plan-name"
@@ -43,9 +60,15 @@ const (
 	// KubeconfigKey is the name of the key in the secret storing cluster credentials.
 	// The secret is created by KEB: https://github.com/kyma-project/control-plane/blob/main/components/kyma-environment-broker/internal/process/steps/lifecycle_manager_kubeconfig.go
 	KubeconfigKey = "config"
+
+	queueBaseDelay = 5 * time.Millisecond
+	queueMaxDelay  = 1000 * time.Second
 )
 
-var errNotFound = errors.New("resource not found")
+var (
+	errNotFound           = errors.New("resource not found")
+	errKubeconfigNotFound = apperrors.Internal("kubeconfig not available").SetReason(apperrors.ErrKubeconfigNotFound) //nolint:gochecknoglobals
+)
 
 type DirectorError struct {
 	message error
@@ -55,24 +78,40 @@ func (e *DirectorError) Error() string {
 	return fmt.Sprintf("error from director: %s", e.message)
 }
 
-//+kubebuilder:rbac:groups=operator.kyma-project.io,resources=kymas,verbs=get;list;watch,namespace=kcp-system
+//+kubebuilder:rbac:groups=operator.kyma-project.io,resources=kymas,verbs=get;list;watch;update,namespace=kcp-system
+//+kubebuilder:rbac:groups=operator.kyma-project.io,resources=kymas/finalizers,verbs=update,namespace=kcp-system
 //+kubebuilder:rbac:groups=operator.kyma-project.io,resources=compassmanagermappings,verbs=create;get;list;delete;watch;update,namespace=kcp-system
 //+kubebuilder:rbac:groups=operator.kyma-project.io,resources=compassmanagermappings/status,verbs=get;update;patch,namespace=kcp-system
 //+kubebuilder:rbac:groups=operator.kyma-project.io,resources=compassmanagermappings/finalizers,verbs=update;get,namespace=kcp-system
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch,namespace=kcp-system
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch,namespace=kcp-system
 
 //go:generate mockery --name=Configurator
 type Configurator interface {
 	// ConfigureCompassRuntimeAgent creates a secret in the Runtime that is used by the Compass Runtime Agent. It must be idempotent.
-	ConfigureCompassRuntimeAgent(kubeconfig []byte, compassRuntimeID, globalAccount string) error
+	ConfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID, globalAccount string, agentConfig v1beta2.AgentConfiguration) error
+	// DeconfigureCompassRuntimeAgent deletes the secret used by the Compass Runtime Agent from the Runtime. It must be idempotent.
+	DeconfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, agentConfig v1beta2.AgentConfiguration) error
+	// VerifyCompassRuntimeAgent checks the secret used by the Compass Runtime Agent in the Runtime.
+	// It returns the list of differences from the expected configuration, empty if the secret is up to date.
+	VerifyCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID, globalAccount string, agentConfig v1beta2.AgentConfiguration) ([]string, error)
 }
 
 //go:generate mockery --name=Registrator
 type Registrator interface {
 	// RegisterInCompass creates Runtime in the Compass system. It must be idempotent.
-	RegisterInCompass(compassRuntimeLabels map[string]interface{}) (string, error)
+	// When runtimeName is empty, the name is generated from the shoot name.
+	RegisterInCompass(ctx context.Context, runtimeName string, compassRuntimeLabels map[string]interface{}) (string, error)
+	// FindInCompass returns the ID of the Runtime already registered in the Compass system for the Kyma runtime described by compassRuntimeLabels.
+	// It returns an empty ID if no such Runtime exists.
+	FindInCompass(ctx context.Context, compassRuntimeLabels map[string]interface{}) (string, error)
 	// DeregisterFromCompass deletes Runtime from Compass system
-	DeregisterFromCompass(compassID, globalAccount string) error
+	DeregisterFromCompass(ctx context.Context, compassID, globalAccount string) error
+	// UpdateCompassRuntimeLabels sets the labels of the Runtime in Compass that differ from compassRuntimeLabels.
+	// It returns an apperrors.AppError with the apperrors.RuntimeNotFound cause when the Runtime doesn't exist in Compass.
+	UpdateCompassRuntimeLabels(ctx context.Context, compassID, globalAccount string, compassRuntimeLabels map[string]interface{}) error
+	// IsRuntimeConnected returns true if the Compass Runtime Agent of the Runtime connected to Compass
+	IsRuntimeConnected(ctx context.Context, compassID, globalAccount string) (bool, error)
 }
 
 type Client interface {
@@ -94,8 +133,13 @@ type CompassManagerReconciler struct {
 	requeueTime              time.Duration
 	requeueTimeForKubeconfig time.Duration
 	enabledRegistration      bool
+	adoptExistingRuntimes    bool
 	cluster                  *ControlPlaneInterface
 	metrics                  metrics.Metrics
+	backoff                  *FailureBackoff
+	routing                  DirectorRouting
+	migrationTimeout         time.Duration
+	recorder                 record.EventRecorder
 }
 
 func NewCompassManagerReconciler(
@@ -106,8 +150,12 @@ func NewCompassManagerReconciler(
 	requeueTime time.Duration,
 	requeueTimeForKubeconfig time.Duration,
 	enabledRegistration bool,
+	adoptExistingRuntimes bool,
 	dryRun bool,
 	metrics metrics.Metrics,
+	backoff *FailureBackoff,
+	routing DirectorRouting,
+	migrationTimeout time.Duration,
 ) *CompassManagerReconciler {
 	return &CompassManagerReconciler{
 		Client:                   mgr.GetClient(),
@@ -118,40 +166,76 @@ func NewCompassManagerReconciler(
 		requeueTime:              requeueTime,
 		requeueTimeForKubeconfig: requeueTimeForKubeconfig,
 		enabledRegistration:      enabledRegistration,
+		adoptExistingRuntimes:    adoptExistingRuntimes,
 		cluster:                  NewControlPlaneInterface(mgr.GetClient(), log, dryRun),
 		metrics:                  metrics,
+		backoff:                  backoff,
+		routing:                  routing,
+		migrationTimeout:         migrationTimeout,
+		recorder:                 mgr.GetEventRecorderFor(ManagedBy),
 	}
 }
 
-func (cm *CompassManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) { // nolint:revive
-	cm.Log.Infof("Reconciliation triggered for Kyma Resource %s", req.Name)
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (cm *CompassManagerReconciler) logger(ctx context.Context) *log.Entry {
+	return logging.FromContextOr(ctx, cm.Log)
+}
 
-	kymaCR, err := cm.cluster.GetKyma(req.NamespacedName)
+func (cm *CompassManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "Reconcile",
+		tracing.AttributeKymaName.String(req.Name), tracing.AttributeKymaNamespace.String(req.Namespace))
+	ctx = logging.IntoContext(ctx, cm.Log.WithFields(log.Fields{
+		logging.FieldKyma:        req.Name,
+		logging.FieldReconcileID: controller.ReconcileIDFromContext(ctx),
+	}))
+	start := time.Now()
+	result, err := cm.reconcile(ctx, req)
+	cm.metrics.ObserveReconcile(time.Since(start), err)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (cm *CompassManagerReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cm.logger(ctx).Infof("Reconciliation triggered for Kyma Resource %s", req.Name)
+
+	kymaCR, err := cm.cluster.GetKyma(ctx, req.NamespacedName)
 
 	// KymaCR doesn't exist - reconcile was triggered by deletion
 	if isNotFound(err) {
-		delErr := cm.handleKymaDeletion(req.NamespacedName)
-		var directorError *DirectorError
-		if errors.As(delErr, &directorError) {
-			return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
-		}
-
-		if delErr != nil {
-			return ctrl.Result{}, errors.Wrapf(delErr, "failed to perform unregistration stage for Kyma %s", req.Name)
-		}
-		return ctrl.Result{}, nil
+		return cm.deregisterRuntime(ctx, req.NamespacedName)
 	}
 
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to obtain Kyma resource %s", req.Name)
 	}
 
+	// KymaCR is being deleted - deregister while it still exists, and release it
+	if !kymaCR.DeletionTimestamp.IsZero() {
+		return cm.handleKymaDeletionAndRemoveFinalizer(ctx, req.NamespacedName, kymaCR)
+	}
+
+	// KymaCR exists, but application-connector module was removed from it
+	if !hasApplicationConnectorModule(kymaCR) {
+		return cm.handleModuleRemoval(ctx, req.NamespacedName, kymaCR)
+	}
+
+	// Kyma resource is managed by Compass Manager, it can't be deleted before it's deregistered
+	if !controllerutil.ContainsFinalizer(&kymaCR, KymaFinalizer) {
+		if err := cm.cluster.AddKymaFinalizer(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to add finalizer to Kyma resource %s", req.Name)
+		}
+	}
+
 	// KymaCR exists, get its kubeconfig
-	kubeconfig, err := cm.cluster.GetKubeconfig(req.NamespacedName)
+	kubeconfig, err := cm.cluster.GetKubeconfig(ctx, req.NamespacedName)
 
 	// Kubeconfig doesn't exist / is empty
 	if isNotFound(err) || len(kubeconfig) == 0 {
-		cm.Log.Infof("Kubeconfig for Kyma resource %s not available. Next attempt in %s", req.Name, cm.requeueTimeForKubeconfig)
+		cm.logger(ctx).Infof("Kubeconfig for Kyma resource %s not available. Next attempt in %s", req.Name, cm.requeueTimeForKubeconfig)
+		condition := s.ConditionFromError(v1beta2.ConditionTypeKubeconfigAvailable, errKubeconfigNotFound)
+		if condErr := cm.cluster.SetCompassMappingConditions(ctx, req.NamespacedName, condition); condErr != nil && !isNotFound(condErr) {
+			cm.logger(ctx).Warnf("Failed to set %s condition for Kyma resource %s: %v", v1beta2.ConditionTypeKubeconfigAvailable, req.Name, condErr)
+		}
 		return ctrl.Result{RequeueAfter: cm.requeueTimeForKubeconfig}, nil
 	}
 
@@ -160,155 +244,396 @@ func (cm *CompassManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	// Kyma exists and has a kubeconfig, get the compass mapping
-	compassRuntimeID, runtimeIDErr := cm.cluster.GetCompassRuntimeID(req.NamespacedName)
+	compassRuntimeID, runtimeIDErr := cm.cluster.GetCompassRuntimeID(ctx, req.NamespacedName)
 
 	if runtimeIDErr != nil && !isNotFound(runtimeIDErr) {
 		return ctrl.Result{}, errors.Wrapf(runtimeIDErr, "failed to obtain Compass Mapping for Kyma resource %s", req.Name)
 	}
 
-	globalAccount, ok := kymaCR.Labels[LabelGlobalAccountID]
-	if !ok {
-		return ctrl.Result{}, errors.Wrap(err, "failed to obtain Global Account label from Kyma CR")
+	if _, ok := kymaCR.Labels[LabelGlobalAccountID]; !ok {
+		return ctrl.Result{}, errors.Errorf("failed to obtain Global Account label from Kyma CR %s", req.Name)
 	}
 
 	/// Part 1 - If compass mapping doesn't exist let's create it and requeue
 	if isNotFound(runtimeIDErr) {
-		return cm.makeNewCompassMappingAndRequeue(req.NamespacedName)
+		return cm.makeNewCompassMappingAndRequeue(ctx, req.NamespacedName)
 	}
 
-	mapping, err := cm.cluster.GetCompassMapping(req.NamespacedName)
+	mapping, err := cm.cluster.GetCompassMapping(ctx, req.NamespacedName)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "failed to obtain Compass Manager Mapping for status checks")
 	}
 	status := s.Number(mapping.Status)
+	globalAccount := mappingGlobalAccount(mapping, kymaCR)
+
+	// Director requests are sent to the backend the Runtime is registered in, or will be registered in
+	backend := cm.routing.Backend(mapping, kymaCR)
+	if mapping.Labels[LabelDirectorBackend] != backend {
+		cm.logger(ctx).Infof("Director backend %s chosen for Kyma resource %s", backend, req.Name)
+		if err := cm.cluster.SetCompassMappingDirectorBackend(ctx, req.NamespacedName, backend); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to record Director backend for Kyma resource %s", req.Name)
+		}
+	}
+	ctx = director.ContextWithBackend(ctx, backend)
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttributeDirectorBackend.String(backend))
+	ctx = logging.WithFields(ctx, log.Fields{logging.FieldGlobalAccount: globalAccount})
+	if compassRuntimeID != "" {
+		trace.SpanFromContext(ctx).SetAttributes(tracing.AttributeRuntimeID.String(compassRuntimeID))
+		ctx = logging.WithFields(ctx, log.Fields{logging.FieldCompassRuntimeID: compassRuntimeID})
+	}
+
+	// Registered Runtime is moved to another Director backend or Global Account on request
+	if compassRuntimeID != "" && (isMigrationRequested(mapping) || isMigrationStarted(mapping)) {
+		source := runtimeLocation{backend: backend, globalAccount: globalAccount}
+		target, migrate := migrationTarget(mapping, source)
+		started, isStarted := startedMigrationTarget(mapping)
+
+		switch {
+		case isStarted && started != target:
+			return cm.retargetMigration(ctx, req.NamespacedName, mapping, started, target, migrate)
+		case !migrate:
+			cm.logger(ctx).Infof("Runtime of Kyma resource %s is already registered in the migration target %s", req.Name, source)
+		case !isStarted:
+			return cm.startMigration(ctx, req.NamespacedName, target)
+		case s.IsStalled(mapping.Status, mapping.Generation):
+			cm.logger(ctx).Infof("Migration of Kyma resource %s to %s is stalled, change the migration target or update the Compass Manager Mapping to retry", req.Name, target)
+			return ctrl.Result{}, nil
+		default:
+			return cm.migrateRuntime(ctx, req.NamespacedName, kymaCR, mapping, kubeconfig, source, target)
+		}
+	}
+
+	if s.IsStalled(mapping.Status, mapping.Generation) {
+		cm.logger(ctx).Infof("Reconciliation of Kyma resource %s is stalled, update the Compass Manager Mapping to retry", req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	kubeconfigAvailable := s.ConditionTrue(v1beta2.ConditionTypeKubeconfigAvailable, s.ReasonKubeconfigFound, "")
 
 	if status == s.Empty {
-		return cm.setStatusAndRequeue(req.NamespacedName, s.Processing)
+		return cm.setStatusAndRequeue(ctx, req.NamespacedName, s.Processing, kubeconfigAvailable)
 	}
 
 	if status&(s.Failed) != 0 {
 		status &= ^s.Failed
-		return cm.setStatusAndRequeue(req.NamespacedName, status|s.Processing)
+		return cm.setStatusAndRequeue(ctx, req.NamespacedName, status|s.Processing, kubeconfigAvailable,
+			s.ConditionFalse(v1beta2.ConditionTypeStalled, s.ReasonRetrying, ""))
 	}
 
 	// From this point we will always deal with Compass Manager Mapping for KymaCR
 	// Part 2 - If compass mapping doesn't contain valid runtime ID - register runtime and requeue
 	if len(compassRuntimeID) == 0 && cm.enabledRegistration {
-		return cm.registerRuntimeInCompassAndRequeue(req.NamespacedName, kymaCR.Labels)
+		return cm.registerRuntimeInCompassAndRequeue(ctx, req.NamespacedName, kymaCR.Labels, mapping)
 	}
 
 	if status&(s.Registered|s.Processing) != s.Registered|s.Processing {
-		cm.metrics.UpdateState(req.Name, s.Registered|s.Processing)
-		return cm.setStatusAndRequeue(req.NamespacedName, s.Registered|s.Processing)
+		cm.metrics.UpdateState(metricsRuntime(req.Name, kymaCR.Labels, mapping), s.Registered|s.Processing)
+		conditions := []metav1.Condition{kubeconfigAvailable}
+		if compassRuntimeID != "" {
+			conditions = append(conditions, s.ConditionTrue(v1beta2.ConditionTypeRuntimeRegistered, s.ReasonRegistered, "Runtime registered with ID "+compassRuntimeID))
+		}
+		return cm.setStatusAndRequeue(ctx, req.NamespacedName, s.Registered|s.Processing, conditions...)
 	}
 
 	// From that moment we will always deal with Compass Manager Mapping with ID of registered Runtime, or feature flag is disabled
-	return cm.configureRuntimeAndSetMappingStatus(req.NamespacedName, kubeconfig, compassRuntimeID, globalAccount)
+	return cm.configureRuntimeAndSetMappingStatus(ctx, req.NamespacedName, kymaCR.Labels, kubeconfig, compassRuntimeID, globalAccount, mapping)
 }
 
-func (cm *CompassManagerReconciler) handleKymaDeletion(name types.NamespacedName) error {
-	compass, err := cm.cluster.GetCompassMapping(name)
+func (cm *CompassManagerReconciler) deregisterRuntime(ctx context.Context, name types.NamespacedName) (ctrl.Result, error) {
+	return cm.deregistrationResult(ctx, name, cm.handleKymaDeletion(ctx, name))
+}
+
+// handleKymaDeletionAndRemoveFinalizer deregisters the Runtime, and removes the finalizer from the Kyma resource once it's done
+func (cm *CompassManagerReconciler) handleKymaDeletionAndRemoveFinalizer(ctx context.Context, name types.NamespacedName, kymaCR kyma.Kyma) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&kymaCR, KymaFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if delErr := cm.handleKymaDeletion(ctx, name); delErr != nil {
+		return cm.deregistrationResult(ctx, name, delErr)
+	}
+	return cm.releaseKyma(ctx, name, kymaCR)
+}
+
+func (cm *CompassManagerReconciler) deregistrationResult(ctx context.Context, name types.NamespacedName, delErr error) (ctrl.Result, error) {
+	var directorError *DirectorError
+	if errors.As(delErr, &directorError) {
+		return cm.retryDeregistration(ctx, name, directorError.message)
+	}
 
+	if delErr != nil {
+		return ctrl.Result{}, errors.Wrapf(delErr, "failed to perform unregistration stage for Kyma %s", name.Name)
+	}
+	cm.backoff.Forget(name)
+	return ctrl.Result{}, nil
+}
+
+// retryDeregistration requeues the failed deregistration with the exponential backoff. It's never stalled, as the Kyma resource
+// can't be released before the Runtime is deregistered: permanent errors and failures beyond the max attempts are retried with the maximum delay.
+func (cm *CompassManagerReconciler) retryDeregistration(ctx context.Context, name types.NamespacedName, opErr error) (ctrl.Result, error) {
+	delay, retry := cm.backoff.Next(name)
+	if !retry || !apperrors.IsRetryable(opErr) {
+		delay = cm.backoff.MaxDelay()
+	}
+	cm.logger(ctx).Infof("Deregistration attempt %d for Kyma resource %s failed. Next attempt in %s", cm.backoff.Failures(name), name.Name, delay)
+	cm.recordEvent(ctx, name, corev1.EventTypeWarning, EventReasonRetrying, "Deregistration attempt %d failed, next attempt in %s", cm.backoff.Failures(name), delay)
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+// retryOrStall requeues the reconciliation of the Kyma resource after the failed operation with the exponential backoff.
+// When the error is permanent, or the operation failed too many times, the mapping is marked as stalled and the reconciliation is not requeued.
+func (cm *CompassManagerReconciler) retryOrStall(ctx context.Context, name types.NamespacedName, opErr error) (ctrl.Result, error) {
+	reason := s.ReasonPermanentError
+	if apperrors.IsRetryable(opErr) {
+		delay, retry := cm.backoff.Next(name)
+		if retry {
+			cm.logger(ctx).Infof("Attempt %d for Kyma resource %s failed. Next attempt in %s", cm.backoff.Failures(name), name.Name, delay)
+			cm.recordEvent(ctx, name, corev1.EventTypeWarning, EventReasonRetrying, "Attempt %d failed, next attempt in %s", cm.backoff.Failures(name), delay)
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		reason = s.ReasonRetriesExhausted
+	}
+	cm.backoff.Forget(name)
+
+	cm.logger(ctx).Errorf("Giving up reconciliation of Kyma resource %s (%s): %v", name.Name, reason, opErr)
+	cm.recordError(ctx, name, EventReasonStalled, "Giving up reconciliation ("+reason+")", opErr)
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name, s.ConditionTrue(v1beta2.ConditionTypeStalled, reason, opErr.Error()))
+	if condErr != nil && !isNotFound(condErr) {
+		return ctrl.Result{}, errors.Wrapf(condErr, "failed to set %s condition for Kyma resource %s", v1beta2.ConditionTypeStalled, name.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+// handleModuleRemoval removes the Compass Runtime Agent configuration from the Runtime, deregisters the Runtime and deletes the mapping,
+// unless the mapping has the Retain module removal policy. The Kyma resource is released once it's no longer registered.
+func (cm *CompassManagerReconciler) handleModuleRemoval(ctx context.Context, name types.NamespacedName, kymaCR kyma.Kyma) (ctrl.Result, error) {
+	mapping, err := cm.cluster.GetCompassMapping(ctx, name)
 	if isNotFound(err) {
-		cm.Log.Warnf("Runtime %s has no compass mapping, nothing to delete", name)
+		cm.logger(ctx).Infof("Application Connector module is not enabled in Kyma resource %s, nothing to do", name.Name)
+		return cm.releaseKyma(ctx, name, kymaCR)
+	}
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to obtain Compass Manager Mapping for Kyma resource %s", name.Name)
+	}
+
+	if mapping.Spec.ModuleRemovalPolicy == v1beta2.ModuleRemovalPolicyRetain {
+		cm.logger(ctx).Infof("Application Connector module removed from Kyma resource %s, keeping the Runtime registered in Compass due to the %s policy", name.Name, v1beta2.ModuleRemovalPolicyRetain)
+		cm.recorder.Eventf(&mapping, corev1.EventTypeNormal, EventReasonRuntimeRetained,
+			"Application Connector module removed, keeping the Runtime registered in Compass due to the %s policy", v1beta2.ModuleRemovalPolicyRetain)
+		return ctrl.Result{}, nil
+	}
+
+	cm.logger(ctx).Infof("Application Connector module removed from Kyma resource %s", name.Name)
+	if delErr := cm.handleKymaDeletion(ctx, name); delErr != nil {
+		return cm.deregistrationResult(ctx, name, delErr)
+	}
+	return cm.releaseKyma(ctx, name, kymaCR)
+}
+
+// releaseKyma removes the finalizer from the Kyma resource which is no longer managed by Compass Manager
+func (cm *CompassManagerReconciler) releaseKyma(ctx context.Context, name types.NamespacedName, kymaCR kyma.Kyma) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&kymaCR, KymaFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	if err := cm.cluster.RemoveKymaFinalizer(ctx, name); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to remove finalizer from Kyma resource %s", name.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (cm *CompassManagerReconciler) handleKymaDeletion(ctx context.Context, name types.NamespacedName) error {
+	compass, err := cm.cluster.GetCompassMapping(ctx, name)
+
+	if isNotFound(err) {
+		cm.logger(ctx).Warnf("Runtime %s has no compass mapping, nothing to delete", name)
 		return nil
 	}
 
 	if err != nil {
-		cm.Log.Warnf("Failed to obtain Compass Mapping for Kyma %s: %v", name.Name, err)
+		cm.logger(ctx).Warnf("Failed to obtain Compass Mapping for Kyma %s: %v", name.Name, err)
 		return err
 	}
 
+	cm.removeAgentConfiguration(ctx, name, compass.Spec.AgentConfiguration)
+
 	runtimeIDFromMapping, ok := compass.Labels[LabelCompassID]
 
 	if ok && runtimeIDFromMapping != "" {
-		globalAccountFromMapping, ok := compass.Labels[LabelGlobalAccountID]
-		if !ok {
-			cm.Log.Warnf("Compass Mapping for %s has no Global Account", name.Name)
+		globalAccountFromMapping := compass.Spec.GlobalAccountID
+		if globalAccountFromMapping == "" {
+			globalAccountFromMapping = compass.Labels[LabelGlobalAccountID]
+		}
+		if globalAccountFromMapping == "" {
+			cm.logger(ctx).Warnf("Compass Mapping for %s has no Global Account", name.Name)
 			return errors.Errorf("Compass Mapping for %s has no Global Account", name.Name)
 		}
+		ctx = logging.WithFields(ctx, log.Fields{logging.FieldGlobalAccount: globalAccountFromMapping, logging.FieldCompassRuntimeID: runtimeIDFromMapping})
+
+		source := runtimeLocation{backend: cm.routing.MappingBackend(compass), globalAccount: globalAccountFromMapping}
+		if err := cm.deregisterMigrationRuntime(ctx, compass, source); err != nil {
+			return err
+		}
 
-		cm.Log.Infof("Runtime deregistration in Compass for Kyma Resource %s", name.Name)
-		err = cm.Registrator.DeregisterFromCompass(runtimeIDFromMapping, globalAccountFromMapping)
+		cm.logger(ctx).Infof("Runtime deregistration in Compass for Kyma Resource %s", name.Name)
+		err = cm.Registrator.DeregisterFromCompass(director.ContextWithBackend(ctx, source.backend), runtimeIDFromMapping, globalAccountFromMapping)
 		if err != nil {
-			cm.Log.Warnf("Failed to deregister Runtime from Compass for Kyma Resource %s: %v", name.Name, err)
+			cm.logger(ctx).Warnf("Failed to deregister Runtime from Compass for Kyma Resource %s: %v", name.Name, err)
+			cm.recorder.Eventf(&compass, corev1.EventTypeWarning, EventReasonDeregistrationFailed,
+				"Failed to deregister Runtime %s from Compass: %s", runtimeIDFromMapping, errorEventDetails(err))
 			return errors.Wrap(&DirectorError{message: err}, "failed to deregister Runtime from Compass")
 		}
-		cm.metrics.IncUnregister(name.Name)
-		cm.metrics.UpdateState(name.Name, s.Empty)
+		// the Kyma resource is already gone when the deregistration was triggered by its deletion
+		kymaCR, _ := cm.cluster.GetKyma(ctx, name)
+		cm.metrics.IncUnregister(metricsRuntime(name.Name, kymaCR.Labels, compass))
 
-		cm.Log.Infof("Runtime %s deregistered from Compass", name.Name)
+		cm.logger(ctx).Infof("Runtime %s deregistered from Compass", name.Name)
+		cm.recorder.Eventf(&compass, corev1.EventTypeNormal, EventReasonRuntimeDeregistered, "Runtime %s deregistered from Compass", runtimeIDFromMapping)
 	} else {
-		cm.Log.Infof("Runtime was not connected in Compass, deleting without deregistering")
+		cm.logger(ctx).Infof("Runtime was not connected in Compass, deleting without deregistering")
 	}
 
-	err = cm.cluster.DeleteCompassMapping(name)
+	err = cm.cluster.DeleteCompassMapping(ctx, name)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete Compass Mapping")
 	}
+	cm.metrics.DeleteRuntime(name.Name)
 	return nil
 }
 
-func (cm *CompassManagerReconciler) makeNewCompassMappingAndRequeue(kymaName types.NamespacedName) (ctrl.Result, error) {
+// removeAgentConfiguration deletes the Compass Runtime Agent secret from the Runtime being deprovisioned, if its kubeconfig still exists.
+// Failures don't block the deregistration, as the Runtime may already be unreachable.
+func (cm *CompassManagerReconciler) removeAgentConfiguration(ctx context.Context, name types.NamespacedName, agentConfig v1beta2.AgentConfiguration) {
+	kubeconfig, err := cm.cluster.GetKubeconfig(ctx, name)
+	if err != nil || len(kubeconfig) == 0 {
+		cm.logger(ctx).Infof("Kubeconfig for Kyma resource %s not available, skipping removal of Compass Runtime Agent configuration", name.Name)
+		return
+	}
+
+	if err := cm.Configurator.DeconfigureCompassRuntimeAgent(ctx, kubeconfig, agentConfig); err != nil {
+		cm.logger(ctx).Warnf("Failed to remove Compass Runtime Agent configuration for Kyma resource %s: %v", name.Name, err)
+		return
+	}
+	cm.logger(ctx).Infof("Compass Runtime Agent configuration for Kyma resource %s removed", name.Name)
+	cm.recordEvent(ctx, name, corev1.EventTypeNormal, EventReasonAgentDeconfigured, "Compass Runtime Agent configuration removed")
+}
+
+func (cm *CompassManagerReconciler) makeNewCompassMappingAndRequeue(ctx context.Context, kymaName types.NamespacedName) (ctrl.Result, error) {
 	// default mode - application-connector module is enabled for the first time in Kyma, we create Compass Manager Mapping
 	runtimeRegistrationType := "newly provisioned Kyma runtime"
+	if cm.adoptExistingRuntimes {
+		// adoption mode - the runtime may have been registered in Compass before, e.g. by the provisioner
+		runtimeRegistrationType = "Kyma runtime which may be already registered in Compass"
+	}
 
-	cm.Log.Infof("Attempting to create Compass Manager Mapping for %s for Kyma resource %s.", runtimeRegistrationType, kymaName.Name)
-	cmerr := cm.cluster.CreateCompassMapping(kymaName)
+	cm.logger(ctx).Infof("Attempting to create Compass Manager Mapping for %s for Kyma resource %s.", runtimeRegistrationType, kymaName.Name)
+	cmerr := cm.cluster.CreateCompassMapping(ctx, kymaName)
 	if cmerr != nil {
+		cm.recordError(ctx, kymaName, EventReasonMappingCreationFailed, "Failed to create Compass Manager Mapping", cmerr)
 		return ctrl.Result{Requeue: true}, errors.Wrapf(cmerr, "failed to create Compass Manager Mapping for %s for Kyma resource ID %s", runtimeRegistrationType, kymaName.Name)
 	}
+	cm.recordEvent(ctx, kymaName, corev1.EventTypeNormal, EventReasonMappingCreated, "Compass Manager Mapping created for %s", runtimeRegistrationType)
 	return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
 }
 
-func (cm *CompassManagerReconciler) registerRuntimeInCompassAndRequeue(kymaName types.NamespacedName, kymaLabels map[string]string) (ctrl.Result, error) {
-	cm.Log.Infof("Attempting to register runtime in compass for Kyma resource %s.", kymaName.Name)
+func (cm *CompassManagerReconciler) registerRuntimeInCompassAndRequeue(ctx context.Context, kymaName types.NamespacedName, kymaLabels map[string]string, mapping v1beta2.CompassManagerMapping) (ctrl.Result, error) {
+	cm.logger(ctx).Infof("Attempting to register runtime in compass for Kyma resource %s.", kymaName.Name)
 
-	newCompassRuntimeID, regError := cm.Registrator.RegisterInCompass(createCompassRuntimeLabels(kymaLabels))
+	newCompassRuntimeID, adopted, regError := cm.registerOrAdoptRuntime(ctx, mapping.Spec.RuntimeName, mappingCompassRuntimeLabels(kymaLabels, mapping.Spec))
 
 	if regError != nil {
-		cm.Log.Errorf("Failed attempt to register runtime for Kyma resource: %s: %v", kymaName.Name, regError)
-		statErr := cm.cluster.SetCompassMappingStatus(kymaName, s.Failed)
+		cm.logger(ctx).Errorf("Failed attempt to register runtime for Kyma resource: %s: %v", kymaName.Name, regError)
+		cm.recordError(ctx, kymaName, EventReasonRegistrationFailed, "Failed to register Runtime in Compass", regError)
+		statErr := cm.cluster.SetCompassMappingStatus(ctx, kymaName, s.Failed, failureConditions(v1beta2.ConditionTypeRuntimeRegistered, regError)...)
 
 		if statErr != nil {
 			return ctrl.Result{Requeue: true}, errors.Wrap(statErr, "failed to set Compass Manager Status after failed attempt to register runtime")
 		}
 
-		return ctrl.Result{Requeue: true}, errors.Wrapf(regError, "failed attempt to register runtime for Kyma resource: %s", kymaName.Name)
+		return cm.retryOrStall(ctx, kymaName, regError)
+	}
+	cm.backoff.Forget(kymaName)
+	ctx = logging.WithFields(ctx, log.Fields{logging.FieldCompassRuntimeID: newCompassRuntimeID})
+
+	registeredCondition := s.ConditionTrue(v1beta2.ConditionTypeRuntimeRegistered, s.ReasonRegistered, "Runtime registered with ID "+newCompassRuntimeID)
+	if adopted {
+		cm.metrics.IncAdopt(metricsRuntime(kymaName.Name, kymaLabels, mapping))
+		cm.logger(ctx).Infof("Runtime %s already registered in Compass, adopting it", newCompassRuntimeID)
+		registeredCondition = s.ConditionTrue(v1beta2.ConditionTypeRuntimeRegistered, s.ReasonAdopted, "Runtime adopted with ID "+newCompassRuntimeID)
+	} else {
+		cm.metrics.IncRegister(metricsRuntime(kymaName.Name, kymaLabels, mapping))
+		cm.logger(ctx).Infof("Runtime %s registered in Compass", newCompassRuntimeID)
+	}
+	cm.metrics.UpdateState(metricsRuntime(kymaName.Name, kymaLabels, mapping), s.Registered|s.Processing)
+	if !adopted && isFirstRegistration(mapping) {
+		cm.metrics.ObserveTimeToRegistered(time.Since(mapping.CreationTimestamp.Time))
 	}
 
-	cm.metrics.IncRegister(kymaName.Name)
-	cm.metrics.UpdateState(kymaName.Name, s.Registered|s.Processing)
-
-	cm.Log.Infof("Runtime %s registered in Compass", newCompassRuntimeID)
-	cmerr := cm.cluster.UpsertCompassMapping(kymaName, newCompassRuntimeID)
+	cmerr := cm.cluster.UpsertCompassMapping(ctx, kymaName, newCompassRuntimeID)
 	if cmerr != nil {
 		return ctrl.Result{Requeue: true}, errors.Wrap(cmerr, "failed to update Compass Manager Mapping with RuntimeID after registration of runtime")
 	}
 
+	if adopted {
+		cm.recordEvent(ctx, kymaName, corev1.EventTypeNormal, EventReasonRuntimeAdopted, "Runtime %s already registered in Compass adopted", newCompassRuntimeID)
+	} else {
+		cm.recordEvent(ctx, kymaName, corev1.EventTypeNormal, EventReasonRuntimeRegistered, "Runtime registered in Compass with ID %s", newCompassRuntimeID)
+	}
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, kymaName,
+		registeredCondition,
+		s.ConditionTrue(v1beta2.ConditionTypeDirectorReachable, s.ReasonDirectorResponded, ""))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions after registration of runtime")
+	}
+
 	return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
 }
 
-func (cm *CompassManagerReconciler) configureRuntimeAndSetMappingStatus(kymaName types.NamespacedName, kubeconfig []byte, compassRuntimeID, globalAccount string) (ctrl.Result, error) {
-	cm.Log.Infof("Attempting to configure Compass Runtime Agent for Runtime %s", compassRuntimeID)
+// registerOrAdoptRuntime registers the Runtime in Compass. In the adoption mode, the ID of the Runtime already registered in Compass is returned instead, if it exists.
+func (cm *CompassManagerReconciler) registerOrAdoptRuntime(ctx context.Context, runtimeName string, compassRuntimeLabels map[string]interface{}) (string, bool, error) {
+	if cm.adoptExistingRuntimes {
+		existingRuntimeID, err := cm.Registrator.FindInCompass(ctx, compassRuntimeLabels)
+		if err != nil {
+			return "", false, err
+		}
+		if existingRuntimeID != "" {
+			return existingRuntimeID, true, nil
+		}
+	}
+
+	newCompassRuntimeID, err := cm.Registrator.RegisterInCompass(ctx, runtimeName, compassRuntimeLabels)
+	return newCompassRuntimeID, false, err
+}
+
+func (cm *CompassManagerReconciler) configureRuntimeAndSetMappingStatus(ctx context.Context, kymaName types.NamespacedName, kymaLabels map[string]string, kubeconfig []byte, compassRuntimeID, globalAccount string, mapping v1beta2.CompassManagerMapping) (ctrl.Result, error) {
+	cm.logger(ctx).Infof("Attempting to configure Compass Runtime Agent for Runtime %s", compassRuntimeID)
 
-	cfgError := cm.Configurator.ConfigureCompassRuntimeAgent(kubeconfig, compassRuntimeID, globalAccount)
+	cfgError := cm.Configurator.ConfigureCompassRuntimeAgent(ctx, kubeconfig, compassRuntimeID, globalAccount, mapping.Spec.AgentConfiguration)
 	if cfgError != nil {
-		cm.Log.Errorf("Failed attempt to configure Compass Runtime Agent for Kyma resource %s", kymaName.Name)
+		cm.logger(ctx).Errorf("Failed attempt to configure Compass Runtime Agent for Kyma resource %s: %v", kymaName.Name, cfgError)
+		cm.recordError(ctx, kymaName, EventReasonAgentConfigurationFailed, "Failed to configure Compass Runtime Agent for Runtime "+compassRuntimeID, cfgError)
 
-		statErr := cm.cluster.SetCompassMappingStatus(kymaName, s.Registered|s.Failed)
+		statErr := cm.cluster.SetCompassMappingStatus(ctx, kymaName, s.Registered|s.Failed, failureConditions(v1beta2.ConditionTypeAgentConfigured, cfgError)...)
 		if statErr != nil {
 			return ctrl.Result{Requeue: true}, errors.Wrap(statErr, "failed to set Compass Manager Status after failed attempt configuration Compass Runtime Agent ")
 		}
 
-		return ctrl.Result{Requeue: true}, errors.Wrapf(cfgError, "failed attempt to configure Compass Runtime Agent for Kyma resource %s", kymaName.Name)
+		return cm.retryOrStall(ctx, kymaName, cfgError)
 	}
+	cm.backoff.Forget(kymaName)
 
-	cm.metrics.IncConfigure(kymaName.Name)
-	cm.metrics.UpdateState(kymaName.Name, s.Registered|s.Configured)
-	cm.Log.Infof("Compass Runtime Agent for Runtime %s configured.", compassRuntimeID)
+	cm.metrics.IncConfigure(metricsRuntime(kymaName.Name, kymaLabels, mapping))
+	cm.metrics.UpdateState(metricsRuntime(kymaName.Name, kymaLabels, mapping), s.Registered|s.Configured)
+	// the Runtime is onboarded when the Compass Runtime Agent is configured for the first time, later configurations don't count
+	if !meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeAgentConfigured) {
+		cm.metrics.ObserveTimeToConfigured(time.Since(mapping.CreationTimestamp.Time))
+	}
+	cm.logger(ctx).Infof("Compass Runtime Agent for Runtime %s configured.", compassRuntimeID)
+	cm.recordEvent(ctx, kymaName, corev1.EventTypeNormal, EventReasonAgentConfigured, "Compass Runtime Agent configured for Runtime %s", compassRuntimeID)
 
-	statErr := cm.cluster.SetCompassMappingStatus(kymaName, s.Registered|s.Configured)
+	statErr := cm.cluster.SetCompassMappingStatus(ctx, kymaName, s.Registered|s.Configured,
+		s.ConditionTrue(v1beta2.ConditionTypeAgentConfigured, s.ReasonConfigured, "Compass Runtime Agent configured for Runtime "+compassRuntimeID),
+		s.ConditionTrue(v1beta2.ConditionTypeDirectorReachable, s.ReasonDirectorResponded, ""))
 	if statErr != nil {
 		return ctrl.Result{Requeue: true}, errors.Wrap(statErr, "failed to set Compass Manager Status after successful configuration Compass Runtime Agent ")
 	}
@@ -316,16 +641,35 @@ func (cm *CompassManagerReconciler) configureRuntimeAndSetMappingStatus(kymaName
 	return ctrl.Result{}, nil
 }
 
-func (cm *CompassManagerReconciler) setStatusAndRequeue(kymaName types.NamespacedName, status s.Status) (ctrl.Result, error) {
-	err := cm.cluster.SetCompassMappingStatus(kymaName, status)
+func (cm *CompassManagerReconciler) setStatusAndRequeue(ctx context.Context, kymaName types.NamespacedName, status s.Status, conditions ...metav1.Condition) (ctrl.Result, error) {
+	err := cm.cluster.SetCompassMappingStatus(ctx, kymaName, status, conditions...)
 	if err != nil {
 		return ctrl.Result{Requeue: true}, errors.Wrap(err, "failed to update Compass Manager Mapping status")
 	}
 	return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
 }
 
+// NewControllerOptions returns the options of the controller reconciling up to maxConcurrentReconciles Kyma resources in parallel.
+// Kyma resources are taken from the queue at most queueQPS times per second (unlimited if not positive), with the burst of at least one,
+// so that a mass onboarding doesn't flood Director, and the ones failing with an error are requeued with the exponential backoff.
+func NewControllerOptions(maxConcurrentReconciles int, queueQPS float64, queueBurst int) controller.Options {
+	queueLimit := rate.Inf
+	if queueQPS > 0 {
+		queueLimit = rate.Limit(queueQPS)
+	}
+
+	return controller.Options{
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](queueBaseDelay, queueMaxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(queueLimit, max(queueBurst, 1))},
+		),
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func (cm *CompassManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// Additional sources, e.g. channels fed by periodic checks, trigger reconciliation of Kyma resources regardless of the event filters.
+func (cm *CompassManagerReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options, sources ...source.Source) error {
 	eventFilters := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
 			return cm.CreateFunc(e.Object)
@@ -338,7 +682,36 @@ func (cm *CompassManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).For(&kyma.Kyma{}).WithEventFilter(eventFilters).Complete(cm)
+	// migration of the Runtime is requested with the annotations of the mapping, which reconciles its Kyma resource
+	migrationFilters := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return migrationRequestChanged(e.ObjectOld, e.ObjectNew) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	// reconciliation of the stalled Kyma resource is retried when the spec of its mapping is updated
+	specFilters := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return predicate.GenerationChangedPredicate{}.Update(e) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	enqueueKyma := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+		mapping, ok := obj.(*v1beta2.CompassManagerMapping)
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: mappingKymaName(*mapping)}}
+	})
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&kyma.Kyma{}, builder.WithPredicates(eventFilters)).
+		Watches(&v1beta2.CompassManagerMapping{}, enqueueKyma, builder.WithPredicates(predicate.Or(migrationFilters, specFilters))).
+		WithOptions(options)
+	for _, src := range sources {
+		controllerBuilder = controllerBuilder.WatchesRawSource(src)
+	}
+	return controllerBuilder.Complete(cm)
 }
 
 func (cm *CompassManagerReconciler) CreateFunc(obj runtime.Object) bool {
@@ -348,15 +721,7 @@ func (cm *CompassManagerReconciler) CreateFunc(obj runtime.Object) bool {
 		return false
 	}
 
-	kymaModules := kymaObj.Status.Modules
-
-	for _, v := range kymaModules {
-		if v.Name == ApplicationConnectorModuleName {
-			return true
-		}
-	}
-
-	return false
+	return hasApplicationConnectorModule(*kymaObj)
 }
 
 func (cm *CompassManagerReconciler) UpdateFunc(oldObj, newObj runtime.Object) bool {
@@ -371,10 +736,17 @@ func (cm *CompassManagerReconciler) UpdateFunc(oldObj, newObj runtime.Object) bo
 		return false
 	}
 
-	oldModules := getModuleNames(oldKymaObj.Status.Modules)
-	newModules := getModuleNames(newKymaObj.Status.Modules)
+	// reconcile when the deletion of the Kyma resource starts
+	if oldKymaObj.DeletionTimestamp.IsZero() && !newKymaObj.DeletionTimestamp.IsZero() {
+		return true
+	}
+
+	// reconcile when the module is added, and when it's removed
+	return hasApplicationConnectorModule(*oldKymaObj) != hasApplicationConnectorModule(*newKymaObj)
+}
 
-	return !slices.Contains(oldModules, ApplicationConnectorModuleName) && slices.Contains(newModules, ApplicationConnectorModuleName)
+func hasApplicationConnectorModule(kymaCR kyma.Kyma) bool {
+	return slices.Contains(getModuleNames(kymaCR.Status.Modules), ApplicationConnectorModuleName)
 }
 
 func getModuleNames(modules []kyma.ModuleStatus) []string {
@@ -404,10 +776,76 @@ func createCompassRuntimeLabels(kymaLabels map[string]string) map[string]interfa
 	runtimeLabels["global_account_id"] = kymaLabels[LabelGlobalAccountID]
 	runtimeLabels["broker_plan_id"] = kymaLabels[LabelBrokerPlanID]
 	runtimeLabels["broker_plan_name"] = kymaLabels[LabelBrokerPlanName]
+	runtimeLabels[idempotencyKeyLabel] = kymaLabels[LabelKymaName]
 
 	return runtimeLabels
 }
 
+// mappingCompassRuntimeLabels returns the labels of the Runtime in Compass, the labels from the mapping spec take precedence over labels derived from the Kyma resource
+func mappingCompassRuntimeLabels(kymaLabels map[string]string, spec v1beta2.CompassManagerMappingSpec) map[string]interface{} {
+	runtimeLabels := createCompassRuntimeLabels(kymaLabels)
+	for key, value := range spec.Labels {
+		runtimeLabels[key] = value
+	}
+	if spec.GlobalAccountID != "" {
+		runtimeLabels["global_account_id"] = spec.GlobalAccountID
+	}
+	return runtimeLabels
+}
+
+// isFirstRegistration returns true if the Runtime of the mapping was never registered before. The Runtime registered again, after it was removed
+// from Compass, already has the RuntimeRegistered condition set to true, or the AgentConfigured condition, which is set only after the registration.
+func isFirstRegistration(mapping v1beta2.CompassManagerMapping) bool {
+	return !meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeRuntimeRegistered) &&
+		meta.FindStatusCondition(mapping.Status.Conditions, v1beta2.ConditionTypeAgentConfigured) == nil
+}
+
+// failureConditions returns the condition of the failed operation, and the DirectorReachable condition if the error came from the Director
+func failureConditions(conditionType string, err error) []metav1.Condition {
+	conditions := []metav1.Condition{s.ConditionFromError(conditionType, err)}
+	if directorCondition, ok := s.DirectorCondition(err); ok {
+		conditions = append(conditions, directorCondition)
+	}
+	return conditions
+}
+
+// mappingGlobalAccount returns the Global Account from the mapping spec, falling back to the Kyma label for mappings created before the spec was introduced
+func mappingGlobalAccount(mapping v1beta2.CompassManagerMapping, kymaCR kyma.Kyma) string {
+	if mapping.Spec.GlobalAccountID != "" {
+		return mapping.Spec.GlobalAccountID
+	}
+	return kymaCR.Labels[LabelGlobalAccountID]
+}
+
+// metricsRuntime returns the labels of the Kyma runtime in the metrics
+func metricsRuntime(kymaName string, kymaLabels map[string]string, mapping v1beta2.CompassManagerMapping) metrics.Runtime {
+	globalAccount := mapping.Spec.GlobalAccountID
+	if globalAccount == "" {
+		globalAccount = kymaLabels[LabelGlobalAccountID]
+	}
+	return metrics.Runtime{
+		KymaName:      kymaName,
+		PlanName:      kymaLabels[LabelBrokerPlanName],
+		GlobalAccount: globalAccount,
+	}
+}
+
+// setMappingSpecDefaults fills the spec fields that were not set by the user with values taken from the Kyma resource
+func setMappingSpecDefaults(spec *v1beta2.CompassManagerMappingSpec, kymaCR kyma.Kyma) {
+	if spec.KymaRef.Name == "" {
+		spec.KymaRef = v1beta2.KymaReference{
+			Name:      kymaCR.Name,
+			Namespace: kymaCR.Namespace,
+		}
+	}
+	if spec.GlobalAccountID == "" {
+		spec.GlobalAccountID = kymaCR.Labels[LabelGlobalAccountID]
+	}
+	if spec.SubaccountID == "" {
+		spec.SubaccountID = kymaCR.Labels[LabelSubaccountID]
+	}
+}
+
 type ControlPlaneInterface struct {
 	log     *log.Logger
 	kubectl Client
@@ -422,10 +860,15 @@ func NewControlPlaneInterface(kubectl Client, log *log.Logger, dryRun bool) *Con
 	}
 }
 
-func (c *ControlPlaneInterface) GetKyma(name types.NamespacedName) (kyma.Kyma, error) {
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (c *ControlPlaneInterface) logger(ctx context.Context) *log.Entry {
+	return logging.FromContextOr(ctx, c.log)
+}
+
+func (c *ControlPlaneInterface) GetKyma(ctx context.Context, name types.NamespacedName) (kyma.Kyma, error) {
 	kymaCR := kyma.Kyma{}
 
-	err := c.kubectl.Get(context.TODO(), name, &kymaCR)
+	err := c.kubectl.Get(ctx, name, &kymaCR)
 	if err != nil {
 		return kymaCR, err
 	}
@@ -440,15 +883,15 @@ func (c *ControlPlaneInterface) GetKyma(name types.NamespacedName) (kyma.Kyma, e
 	return kymaCR, nil
 }
 
-func (c *ControlPlaneInterface) GetCompassMapping(name types.NamespacedName) (v1beta1.CompassManagerMapping, error) {
-	mapping := v1beta1.CompassManagerMapping{}
+func (c *ControlPlaneInterface) GetCompassMapping(ctx context.Context, name types.NamespacedName) (v1beta2.CompassManagerMapping, error) {
+	mapping := v1beta2.CompassManagerMapping{}
 
-	mappingList := &v1beta1.CompassManagerMappingList{}
+	mappingList := &v1beta2.CompassManagerMappingList{}
 	labelSelector := labels.SelectorFromSet(map[string]string{
 		LabelKymaName: name.Name,
 	})
 
-	err := c.kubectl.List(context.TODO(), mappingList, &client.ListOptions{
+	err := c.kubectl.List(ctx, mappingList, &client.ListOptions{
 		LabelSelector: labelSelector,
 		Namespace:     name.Namespace,
 	})
@@ -473,15 +916,15 @@ func (c *ControlPlaneInterface) GetCompassMapping(name types.NamespacedName) (v1
 	return mapping, nil
 }
 
-func (c *ControlPlaneInterface) DeleteCompassMapping(name types.NamespacedName) error {
-	mapping, err := c.GetCompassMapping(name)
+func (c *ControlPlaneInterface) DeleteCompassMapping(ctx context.Context, name types.NamespacedName) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
 	if err != nil {
 		return err
 	}
 
-	err = c.RemoveCMFinalizer(name)
+	err = c.RemoveCMFinalizer(ctx, name)
 	if err != nil {
-		c.log.Warnf("Couldn't remove finalizer for %s", name)
+		c.logger(ctx).Warnf("Couldn't remove finalizer for %s", name)
 		return err
 	}
 
@@ -489,11 +932,11 @@ func (c *ControlPlaneInterface) DeleteCompassMapping(name types.NamespacedName)
 		return nil
 	}
 
-	return c.kubectl.Delete(context.TODO(), &mapping)
+	return c.kubectl.Delete(ctx, &mapping)
 }
 
-func (c *ControlPlaneInterface) RemoveCMFinalizer(name types.NamespacedName) error {
-	mapping, err := c.GetCompassMapping(name)
+func (c *ControlPlaneInterface) RemoveCMFinalizer(ctx context.Context, name types.NamespacedName) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -505,16 +948,46 @@ func (c *ControlPlaneInterface) RemoveCMFinalizer(name types.NamespacedName) err
 		}
 	}
 
-	return c.kubectl.Update(context.TODO(), &mapping)
+	return c.kubectl.Update(ctx, &mapping)
 }
 
-func (c *ControlPlaneInterface) GetKubeconfig(name types.NamespacedName) ([]byte, error) {
+// AddKymaFinalizer adds the Compass Manager finalizer to the Kyma resource. The finalizer is not added in the dry run mode.
+func (c *ControlPlaneInterface) AddKymaFinalizer(ctx context.Context, name types.NamespacedName) error {
+	if c.dry {
+		c.logger(ctx).Infof("[DRY] Add finalizer to Kyma resource %s", name.Name)
+		return nil
+	}
+
+	kymaCR, err := c.GetKyma(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !controllerutil.AddFinalizer(&kymaCR, KymaFinalizer) {
+		return nil
+	}
+	return c.kubectl.Update(ctx, &kymaCR)
+}
+
+func (c *ControlPlaneInterface) RemoveKymaFinalizer(ctx context.Context, name types.NamespacedName) error {
+	kymaCR, err := c.GetKyma(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !controllerutil.RemoveFinalizer(&kymaCR, KymaFinalizer) {
+		return nil
+	}
+	return c.kubectl.Update(ctx, &kymaCR)
+}
+
+func (c *ControlPlaneInterface) GetKubeconfig(ctx context.Context, name types.NamespacedName) ([]byte, error) {
 	secretList := &corev1.SecretList{}
 	labelSelector := labels.SelectorFromSet(map[string]string{
 		LabelKymaName: name.Name,
 	})
 
-	err := c.kubectl.List(context.TODO(), secretList, &client.ListOptions{
+	err := c.kubectl.List(ctx, secretList, &client.ListOptions{
 		LabelSelector: labelSelector,
 		Namespace:     name.Namespace,
 	})
@@ -533,8 +1006,8 @@ func (c *ControlPlaneInterface) GetKubeconfig(name types.NamespacedName) ([]byte
 	return kubecfg.Data[KubeconfigKey], nil
 }
 
-func (c *ControlPlaneInterface) UpsertCompassMapping(name types.NamespacedName, compassRuntimeID string) error {
-	kymaCR, err := c.GetKyma(name)
+func (c *ControlPlaneInterface) UpsertCompassMapping(ctx context.Context, name types.NamespacedName, compassRuntimeID string) error {
+	kymaCR, err := c.GetKyma(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -549,16 +1022,17 @@ func (c *ControlPlaneInterface) UpsertCompassMapping(name types.NamespacedName,
 		labels[LabelDryRun] = "Yes"
 	}
 
-	existingMapping, err := c.GetCompassMapping(name)
+	existingMapping, err := c.GetCompassMapping(ctx, name)
 
 	if isNotFound(err) {
-		newMapping := &v1beta1.CompassManagerMapping{}
+		newMapping := &v1beta2.CompassManagerMapping{}
 		newMapping.Name = name.Name
 		newMapping.Namespace = name.Namespace
 		newMapping.Labels = labels
 		newMapping.Finalizers = []string{Finalizer}
+		setMappingSpecDefaults(&newMapping.Spec, kymaCR)
 
-		cerr := c.kubectl.Create(context.TODO(), newMapping)
+		cerr := c.kubectl.Create(ctx, newMapping)
 		if cerr != nil {
 			return cerr
 		}
@@ -569,8 +1043,13 @@ func (c *ControlPlaneInterface) UpsertCompassMapping(name types.NamespacedName,
 		return err
 	}
 
+	// the Runtime stays in the Director backend it was routed to
+	if backend := existingMapping.Labels[LabelDirectorBackend]; backend != "" {
+		labels[LabelDirectorBackend] = backend
+	}
 	existingMapping.Labels = labels
-	err = c.kubectl.Update(context.TODO(), &existingMapping)
+	setMappingSpecDefaults(&existingMapping.Spec, kymaCR)
+	err = c.kubectl.Update(ctx, &existingMapping)
 	if err != nil {
 		return err
 	}
@@ -578,8 +1057,8 @@ func (c *ControlPlaneInterface) UpsertCompassMapping(name types.NamespacedName,
 	return err
 }
 
-func (c *ControlPlaneInterface) CreateCompassMapping(name types.NamespacedName) error {
-	kymaCR, err := c.GetKyma(name)
+func (c *ControlPlaneInterface) CreateCompassMapping(ctx context.Context, name types.NamespacedName) error {
+	kymaCR, err := c.GetKyma(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -594,29 +1073,84 @@ func (c *ControlPlaneInterface) CreateCompassMapping(name types.NamespacedName)
 		labels[LabelDryRun] = "Yes"
 	}
 
-	newMapping := v1beta1.CompassManagerMapping{}
+	newMapping := v1beta2.CompassManagerMapping{}
 	newMapping.Name = name.Name
 	newMapping.Namespace = name.Namespace
 	newMapping.Labels = labels
 	newMapping.Finalizers = []string{Finalizer}
+	setMappingSpecDefaults(&newMapping.Spec, kymaCR)
 
-	err = c.kubectl.Create(context.TODO(), &newMapping)
+	err = c.kubectl.Create(ctx, &newMapping)
 	return err
 }
 
+// SetCompassMappingDirectorBackend records the Director backend the Runtime is registered in on an existing CompassManagerMapping
+func (c *ControlPlaneInterface) SetCompassMappingDirectorBackend(ctx context.Context, name types.NamespacedName, backend string) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	mapping.Labels[LabelDirectorBackend] = backend
+	return c.kubectl.Update(ctx, &mapping)
+}
+
+// SetCompassMappingAnnotations sets the annotations of an existing CompassManagerMapping, the ones with empty values are removed
+func (c *ControlPlaneInterface) SetCompassMappingAnnotations(ctx context.Context, name types.NamespacedName, annotations map[string]string) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if mapping.Annotations == nil {
+		mapping.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		if value == "" {
+			delete(mapping.Annotations, key)
+			continue
+		}
+		mapping.Annotations[key] = value
+	}
+	return c.kubectl.Update(ctx, &mapping)
+}
+
+// CompleteCompassMappingMigration binds an existing CompassManagerMapping to the migrated Runtime, and removes the migration annotations
+func (c *ControlPlaneInterface) CompleteCompassMappingMigration(ctx context.Context, name types.NamespacedName, compassRuntimeID, backend, globalAccount string) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if mapping.Labels == nil {
+		mapping.Labels = make(map[string]string)
+	}
+	mapping.Labels[LabelCompassID] = compassRuntimeID
+	mapping.Labels[LabelDirectorBackend] = backend
+	mapping.Labels[LabelGlobalAccountID] = globalAccount
+	mapping.Spec.GlobalAccountID = globalAccount
+	delete(mapping.Annotations, AnnotationMigrationTargetBackend)
+	delete(mapping.Annotations, AnnotationMigrationTargetGlobalAccount)
+	delete(mapping.Annotations, AnnotationMigrationRuntimeID)
+	delete(mapping.Annotations, AnnotationMigrationStartedBackend)
+	delete(mapping.Annotations, AnnotationMigrationStartedGlobalAccount)
+	delete(mapping.Annotations, AnnotationMigrationStartTime)
+	return c.kubectl.Update(ctx, &mapping)
+}
+
 // GetCompassRuntimeID returns `errNotFound` if the mapping exists, but doesn't have the label
-func (c *ControlPlaneInterface) GetCompassRuntimeID(name types.NamespacedName) (string, error) {
-	mapping, err := c.GetCompassMapping(name)
+func (c *ControlPlaneInterface) GetCompassRuntimeID(ctx context.Context, name types.NamespacedName) (string, error) {
+	mapping, err := c.GetCompassMapping(ctx, name)
 	if err != nil {
 		return "", err
 	}
 	return mapping.Labels[LabelCompassID], nil
 }
 
-// SetCompassMappingStatus sets the registered and configured on an existing CompassManagerMapping
+// SetCompassMappingStatus sets the registered and configured on an existing CompassManagerMapping, together with the given conditions
 // If error occurs - logs it and returns
-func (c *ControlPlaneInterface) SetCompassMappingStatus(name types.NamespacedName, status s.Status) error {
-	mapping, err := c.GetCompassMapping(name)
+func (c *ControlPlaneInterface) SetCompassMappingStatus(ctx context.Context, name types.NamespacedName, status s.Status, conditions ...metav1.Condition) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -625,17 +1159,36 @@ func (c *ControlPlaneInterface) SetCompassMappingStatus(name types.NamespacedNam
 	configured := status&s.Configured != 0
 	state := s.StateText(status)
 
-	mapping.Status = v1beta1.CompassManagerMappingStatus{
-		Registered: registered,
-		Configured: configured,
-		State:      state,
+	mapping.Status.Registered = registered
+	mapping.Status.Configured = configured
+	mapping.Status.State = state
+	mapping.Status.ObservedGeneration = mapping.Generation
+	s.SetConditions(&mapping.Status, mapping.Generation, conditions...)
+	if state == s.ReadyState {
+		mapping.Status.LastError = ""
 	}
 
-	err = c.kubectl.Status().Update(context.TODO(), &mapping)
+	err = c.kubectl.Status().Update(ctx, &mapping)
 	if err != nil {
-		c.log.Warnf("Failed to update Compass Mapping Status for %s: %v", name.Name, err)
+		c.logger(ctx).Warnf("Failed to update Compass Mapping Status for %s: %v", name.Name, err)
 	} else {
-		c.log.Infof("Updated Compass Mapping Status for %s: registered=%v, configured=%v, state=%s", name.Name, registered, configured, state)
+		c.logger(ctx).Infof("Updated Compass Mapping Status for %s: registered=%v, configured=%v, state=%s", name.Name, registered, configured, state)
+	}
+	return err
+}
+
+// SetCompassMappingConditions updates the conditions of an existing CompassManagerMapping, leaving the rest of the status untouched
+func (c *ControlPlaneInterface) SetCompassMappingConditions(ctx context.Context, name types.NamespacedName, conditions ...metav1.Condition) error {
+	mapping, err := c.GetCompassMapping(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	s.SetConditions(&mapping.Status, mapping.Generation, conditions...)
+
+	err = c.kubectl.Status().Update(ctx, &mapping)
+	if err != nil {
+		c.logger(ctx).Warnf("Failed to update Compass Mapping conditions for %s: %v", name.Name, err)
 	}
 	return err
 }
@@ -643,3 +1196,9 @@ func (c *ControlPlaneInterface) SetCompassMappingStatus(name types.NamespacedNam
 func isNotFound(err error) bool {
 	return k8serrors.IsNotFound(err) || errors.Is(err, errNotFound)
 }
+
+// isRuntimeNotFound returns true if the Runtime doesn't exist in Compass
+func isRuntimeNotFound(err error) bool {
+	var appErr apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Cause() == apperrors.RuntimeNotFound
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"time"
 
-	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 	"github.com/kyma-project/lifecycle-manager/api/shared"
 	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
 	. "github.com/onsi/ginkgo/v2" //nolint:revive
 	. "github.com/onsi/gomega"    //nolint:revive
+	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
@@ -41,7 +45,7 @@ var _ = Describe("Compass Manager controller", func() {
 			Expect(k8sClient.Create(context.Background(), &kymaCR)).To(Succeed())
 
 			By("Wait for mapping")
-			var mapping v1beta1.CompassManagerMapping
+			var mapping v1beta2.CompassManagerMapping
 			Eventually(func() bool {
 				var err error
 				mapping, err = getCompassMapping(kymaCR.Name)
@@ -53,6 +57,13 @@ var _ = Describe("Compass Manager controller", func() {
 			By("Verify status")
 			Expect(mapping.Status.Registered).To(BeTrue())
 			Expect(mapping.Status.Configured).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeRuntimeRegistered)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeAgentConfigured)).To(BeTrue())
+			Expect(mapping.Status.ObservedGeneration).To(Equal(mapping.Generation))
+
+			By("Verify spec")
+			Expect(mapping.Spec.KymaRef.Name).To(Equal(kymaName))
+			Expect(mapping.Spec.GlobalAccountID).To(Equal("globalAccount"))
 
 		},
 			Entry("Runtime successfully registered, and Compass Runtime Agent's configuration created", "all-good"),
@@ -104,6 +115,13 @@ var _ = Describe("Compass Manager controller", func() {
 				return err == nil && label != ""
 			}, clientTimeout, clientInterval).Should(BeTrue())
 
+			Eventually(func() bool {
+				var obj kyma.Kyma
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: kymaCR.Name, Namespace: kymaCustomResourceNamespace}, &obj)
+
+				return err == nil && controllerutil.ContainsFinalizer(&obj, KymaFinalizer)
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
 			By("Delete Kyma resource")
 			Expect(k8sClient.Delete(context.Background(), &kymaCR)).To(Succeed())
 
@@ -112,6 +130,16 @@ var _ = Describe("Compass Manager controller", func() {
 
 				return errors.IsNotFound(err) && label == ""
 			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			By("Kyma resource is released after deregistration")
+			Eventually(func() bool {
+				var obj kyma.Kyma
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: kymaCR.Name, Namespace: kymaCustomResourceNamespace}, &obj)
+
+				return errors.IsNotFound(err)
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			mockConfigurator.AssertCalled(GinkgoT(), "DeconfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-"+kymaName), v1beta2.AgentConfiguration{})
 		},
 			Entry("Runtime successfully unregistered", "unregister-runtime"),
 			Entry("The first attempt to unregister Runtime failed, and retry succeeded", "unregister-runtime-fails"),
@@ -134,6 +162,16 @@ var _ = Describe("Compass Manager controller", func() {
 				return err == nil && label != "" && state == mappingCRReadyState
 			}, clientTimeout, clientInterval).Should(BeTrue())
 
+			By("Keep the Runtime registered when the Application Connector module is removed")
+			Eventually(func() error {
+				mapping, err := getCompassMapping(kymaCR.Name)
+				if err != nil {
+					return err
+				}
+				mapping.Spec.ModuleRemovalPolicy = v1beta2.ModuleRemovalPolicyRetain
+				return k8sClient.Update(context.Background(), &mapping)
+			}, clientTimeout, clientInterval).ShouldNot(HaveOccurred())
+
 			By("Disable the Application Connector module")
 			modifiedKyma, err := modifyKymaModules(kymaCR.Name, kymaCustomResourceNamespace, nil)
 			Expect(err).NotTo(HaveOccurred())
@@ -157,8 +195,101 @@ var _ = Describe("Compass Manager controller", func() {
 			Entry("Token successfully refreshed", "refresh-token"),
 		)
 	})
+
+	Context("After successful runtime registration when user disables Application Connector module", func() {
+		DescribeTable("the runtime should be deregistered from Compass System and Compass Runtime Agent configuration removed", func(kymaName string) {
+			By("Create secret with credentials")
+			secret := createCredentialsSecret(kymaName)
+			Expect(k8sClient.Create(context.Background(), &secret)).To(Succeed())
+
+			By("Create Kyma Resource")
+			kymaCR := createKymaResource(kymaName)
+			Expect(k8sClient.Create(context.Background(), &kymaCR)).To(Succeed())
+
+			Eventually(func() bool {
+				label, state, err := getCompassMappingCompassIDAndState(kymaCR.Name)
+
+				return err == nil && label != "" && state == mappingCRReadyState
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			By("Disable the Application Connector module")
+			Eventually(func() error {
+				modifiedKyma, err := modifyKymaModules(kymaCR.Name, kymaCustomResourceNamespace, nil)
+				if err != nil {
+					return err
+				}
+				return k8sClient.Update(context.Background(), modifiedKyma)
+			}, clientTimeout, clientInterval).ShouldNot(HaveOccurred())
+
+			Eventually(func() bool {
+				_, _, err := getCompassMappingCompassIDAndState(kymaCR.Name)
+
+				return errors.IsNotFound(err)
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			mockConfigurator.AssertCalled(GinkgoT(), "DeconfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-"+kymaName), v1beta2.AgentConfiguration{})
+			mockRegistrator.AssertCalled(GinkgoT(), "DeregisterFromCompass", mock.Anything, "id-"+kymaName, "globalAccount")
+		},
+			Entry("Runtime successfully unregistered", "disable-module"),
+		)
+	})
+
+	Context("After successful runtime registration when the migration to another Global Account stalls", func() {
+		It("resumes the migration when the target annotation is changed", func() {
+			kymaName := "migration-stalls"
+
+			By("Create secret with credentials")
+			secret := createCredentialsSecret(kymaName)
+			Expect(k8sClient.Create(context.Background(), &secret)).To(Succeed())
+
+			By("Create Kyma Resource")
+			kymaCR := createKymaResource(kymaName)
+			Expect(k8sClient.Create(context.Background(), &kymaCR)).To(Succeed())
+
+			Eventually(func() bool {
+				label, state, err := getCompassMappingCompassIDAndState(kymaCR.Name)
+
+				return err == nil && label == "id-"+kymaName && state == mappingCRReadyState
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			By("Request the migration to the Global Account the registration fails in")
+			Expect(setCompassMappingAnnotation(kymaName, AnnotationMigrationTargetGlobalAccount, "ga-failing")).To(Succeed())
+
+			Eventually(func() bool {
+				mapping, err := getCompassMapping(kymaName)
+
+				return err == nil && meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeStalled)
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			By("Change the migration target")
+			Expect(setCompassMappingAnnotation(kymaName, AnnotationMigrationTargetGlobalAccount, "ga-target")).To(Succeed())
+
+			Eventually(func() bool {
+				mapping, err := getCompassMapping(kymaName)
+
+				return err == nil && mapping.Labels[LabelCompassID] == "id-migration-target" && mapping.Spec.GlobalAccountID == "ga-target" &&
+					!meta.IsStatusConditionTrue(mapping.Status.Conditions, v1beta2.ConditionTypeStalled)
+			}, clientTimeout, clientInterval).Should(BeTrue())
+
+			mockRegistrator.AssertCalled(GinkgoT(), "DeregisterFromCompass", mock.Anything, "id-"+kymaName, "globalAccount")
+		})
+	})
 })
 
+func setCompassMappingAnnotation(kymaName, key, value string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		mapping, err := getCompassMapping(kymaName)
+		if err != nil {
+			return err
+		}
+		if mapping.Annotations == nil {
+			mapping.Annotations = make(map[string]string)
+		}
+		mapping.Annotations[key] = value
+		return k8sClient.Update(context.Background(), &mapping)
+	})
+}
+
 func createNamespace(name string) error {
 	namespace := corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -221,8 +352,8 @@ func getCompassMappingCompassIDAndState(kymaName string) (string, string, error)
 	return labels[LabelCompassID], obj.Status.State, nil
 }
 
-func getCompassMapping(kymaName string) (v1beta1.CompassManagerMapping, error) {
-	var obj v1beta1.CompassManagerMapping
+func getCompassMapping(kymaName string) (v1beta2.CompassManagerMapping, error) {
+	var obj v1beta2.CompassManagerMapping
 	key := types.NamespacedName{Name: kymaName, Namespace: kymaCustomResourceNamespace}
 
 	err := cm.Client.Get(context.Background(), key, &obj)
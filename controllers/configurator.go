@@ -2,18 +2,23 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/controllers/metrics"
 	"github.com/kyma-project/compass-manager/internal/apperrors"
 	"github.com/kyma-project/compass-manager/internal/director"
-	"github.com/kyma-project/compass-manager/internal/util"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	core "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -22,84 +27,168 @@ const (
 	AgentConfigurationSecretName   = "compass-agent-configuration"
 	runtimeAgentComponentNameSpace = "kyma-system"
 	maxTokenLength                 = 100
+	// runtimeAPITimeout limits the requests to the API server of the Runtime, which may be unreachable
+	runtimeAPITimeout = 30 * time.Second
+
+	agentConfigConnectorURL = "CONNECTOR_URL"
+	agentConfigRuntimeID    = "RUNTIME_ID"
+	agentConfigTenant       = "TENANT"
+	agentConfigToken        = "TOKEN"
 )
 
 type RuntimeAgentConfigurator struct {
 	Client              director.Client
 	ConnectorURLPattern string
 	Log                 *logrus.Logger
+	metrics             metrics.Metrics
 }
 
-func NewRuntimeAgentConfigurator(directorClient director.Client, connectorURLPattern string, log *logrus.Logger) *RuntimeAgentConfigurator {
+func NewRuntimeAgentConfigurator(directorClient director.Client, connectorURLPattern string, log *logrus.Logger, metrics metrics.Metrics) *RuntimeAgentConfigurator {
 	return &RuntimeAgentConfigurator{
 		Client:              directorClient,
 		ConnectorURLPattern: connectorURLPattern,
 		Log:                 log,
+		metrics:             metrics,
 	}
 }
 
-func (r *RuntimeAgentConfigurator) ConfigureCompassRuntimeAgent(kubeconfig []byte, compassRuntimeID, globalAccount string) error {
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (r *RuntimeAgentConfigurator) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContextOr(ctx, r.Log)
+}
+
+func (r *RuntimeAgentConfigurator) ConfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID, globalAccount string, agentConfig v1beta2.AgentConfiguration) error {
 	kubeClient, err := r.prepareKubeClient(kubeconfig)
 	if err != nil {
-		return err
+		return apperrors.Internalf("Failed to create client for the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrKubeconfigInvalid)
 	}
 
-	token, err := r.fetchCompassToken(compassRuntimeID, globalAccount)
+	token, err := r.fetchCompassToken(ctx, compassRuntimeID, globalAccount)
 	if err != nil {
 		return err
 	}
 
-	err = r.upsertCompassRuntimeAgentSecret(kubeClient, token, compassRuntimeID, globalAccount)
+	err = r.upsertCompassRuntimeAgentSecret(ctx, kubeClient, agentSecretName(agentConfig), token, compassRuntimeID, globalAccount)
 	if err != nil {
-		return err
+		return apperrors.Internalf("Failed to upsert Compass Runtime Agent secret in the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrRuntimeAPIRequestFailed)
 	}
 	return nil
 }
 
-func (r *RuntimeAgentConfigurator) upsertCompassRuntimeAgentSecret(kubeClient kubernetes.Interface, token graphql.OneTimeTokenForRuntimeExt, compassRuntimeID, globalAccount string) error {
+// DeconfigureCompassRuntimeAgent deletes the Compass Runtime Agent secret from the Runtime. It succeeds if the secret doesn't exist.
+func (r *RuntimeAgentConfigurator) DeconfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, agentConfig v1beta2.AgentConfiguration) error {
+	kubeClient, err := r.prepareKubeClient(kubeconfig)
+	if err != nil {
+		return apperrors.Internalf("Failed to create client for the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrKubeconfigInvalid)
+	}
+
+	secretName := agentSecretName(agentConfig)
+	err = kubeClient.CoreV1().Secrets(secretName.Namespace).Delete(ctx, secretName.Name, meta.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return apperrors.Internalf("Failed to delete Compass Runtime Agent secret from the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrRuntimeAPIRequestFailed)
+	}
+	return nil
+}
+
+func (r *RuntimeAgentConfigurator) VerifyCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID, globalAccount string, agentConfig v1beta2.AgentConfiguration) ([]string, error) {
+	kubeClient, err := r.prepareKubeClient(kubeconfig)
+	if err != nil {
+		return nil, apperrors.Internalf("Failed to create client for the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrKubeconfigInvalid)
+	}
+
+	secretName := agentSecretName(agentConfig)
+	secret, err := kubeClient.CoreV1().Secrets(secretName.Namespace).Get(ctx, secretName.Name, meta.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return []string{fmt.Sprintf("secret %s not found", secretName)}, nil
+	}
+	if err != nil {
+		return nil, apperrors.Internalf("Failed to get Compass Runtime Agent secret from the Runtime: %s", err.Error()).SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrRuntimeAPIRequestFailed)
+	}
+
+	return agentSecretDrift(secret.Data, compassRuntimeID, globalAccount, r.ConnectorURLPattern), nil
+}
+
+// agentSecretDrift returns the keys of the Compass Runtime Agent secret that differ from the expected configuration.
+// The Connector URL comes with the one-time token, so it is only checked against the expected pattern.
+func agentSecretDrift(data map[string][]byte, compassRuntimeID, globalAccount, connectorURLPattern string) []string {
+	var drift []string
+
+	if string(data[agentConfigRuntimeID]) != compassRuntimeID {
+		drift = append(drift, agentConfigRuntimeID)
+	}
+	if string(data[agentConfigTenant]) != globalAccount {
+		drift = append(drift, agentConfigTenant)
+	}
+	connectorURL := string(data[agentConfigConnectorURL])
+	if connectorURL == "" || !strings.HasSuffix(connectorURL, connectorURLPattern) {
+		drift = append(drift, agentConfigConnectorURL)
+	}
+
+	return drift
+}
+
+func (r *RuntimeAgentConfigurator) upsertCompassRuntimeAgentSecret(ctx context.Context, kubeClient kubernetes.Interface, secretName types.NamespacedName, token graphql.OneTimeTokenForRuntimeExt, compassRuntimeID, globalAccount string) (err error) {
+	ctx, span := tracing.Start(ctx, "Runtime UpsertAgentSecret",
+		tracing.AttributeSecretName.String(secretName.Name),
+		tracing.AttributeSecretNamespace.String(secretName.Namespace),
+		tracing.AttributeRuntimeID.String(compassRuntimeID))
+	defer func() { tracing.End(span, err) }()
+
 	configurationData := map[string]string{
-		"CONNECTOR_URL": token.ConnectorURL,
-		"RUNTIME_ID":    compassRuntimeID,
-		"TENANT":        globalAccount,
-		"TOKEN":         token.Token,
+		agentConfigConnectorURL: token.ConnectorURL,
+		agentConfigRuntimeID:    compassRuntimeID,
+		agentConfigTenant:       globalAccount,
+		agentConfigToken:        token.Token,
 	}
 
 	secret := &core.Secret{
 		ObjectMeta: meta.ObjectMeta{
-			Name:      AgentConfigurationSecretName,
-			Namespace: runtimeAgentComponentNameSpace,
+			Name:      secretName.Name,
+			Namespace: secretName.Namespace,
 		},
 		StringData: configurationData,
 	}
 
-	secretInterface := kubeClient.CoreV1().Secrets(runtimeAgentComponentNameSpace)
+	secretInterface := kubeClient.CoreV1().Secrets(secretName.Namespace)
 
-	_, err := secretInterface.Get(context.TODO(), AgentConfigurationSecretName, meta.GetOptions{})
+	_, err = secretInterface.Get(ctx, secretName.Name, meta.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			_, err = secretInterface.Create(context.TODO(), secret, meta.CreateOptions{})
+			_, err = secretInterface.Create(ctx, secret, meta.CreateOptions{})
 			return err
 		}
 	}
-	_, err = secretInterface.Update(context.TODO(), secret, meta.UpdateOptions{})
+	_, err = secretInterface.Update(ctx, secret, meta.UpdateOptions{})
 	return err
 }
 
+// agentSecretName returns the location of the Compass Runtime Agent secret, using the defaults for fields not set in the mapping
+func agentSecretName(agentConfig v1beta2.AgentConfiguration) types.NamespacedName {
+	name := types.NamespacedName{
+		Name:      agentConfig.SecretName,
+		Namespace: agentConfig.SecretNamespace,
+	}
+	if name.Name == "" {
+		name.Name = AgentConfigurationSecretName
+	}
+	if name.Namespace == "" {
+		name.Namespace = runtimeAgentComponentNameSpace
+	}
+	return name
+}
+
 func (r *RuntimeAgentConfigurator) prepareKubeClient(kubeconfig []byte) (kubernetes.Interface, error) {
 	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
 	if err != nil {
 		return nil, err
 	}
+	config.Timeout = runtimeAPITimeout
+	config.Wrap(r.metrics.InstrumentRuntimeAPI)
 	return kubernetes.NewForConfig(config)
 }
 
-func (r *RuntimeAgentConfigurator) fetchCompassToken(compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, error) {
-	var token graphql.OneTimeTokenForRuntimeExt
-	err := util.RetryOnError(retryTime*time.Second, attempts, "Error while refreshing OneTime token in Director: %s", func() (err apperrors.AppError) {
-		token, err = r.Client.GetConnectionToken(compassID, globalAccount)
-		return
-	})
-
+func (r *RuntimeAgentConfigurator) fetchCompassToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, error) {
+	token, err := r.Client.GetConnectionToken(ctx, compassID, globalAccount)
 	if err != nil {
 		return graphql.OneTimeTokenForRuntimeExt{}, err
 	}
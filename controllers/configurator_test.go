@@ -1,19 +1,23 @@
 package controllers
 
 import (
+	"context"
+	"maps"
 	"testing"
 
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/controllers/metrics"
 	"github.com/kyma-project/compass-manager/internal/director/mocks"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAppError(t *testing.T) {
 	t.Run("should succeed after fetching correct Compass Token", func(t *testing.T) {
 		mockDirectorClient := mocks.Client{}
-		mockDirectorClient.On("GetConnectionToken", "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
+		mockDirectorClient.On("GetConnectionToken", mock.Anything, "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
 			OneTimeTokenForRuntime: graphql.OneTimeTokenForRuntime{
 				TokenWithURL: graphql.TokenWithURL{
 					Token:        "dGVzdFRva2VuQmFzZWQ2NA==",
@@ -22,16 +26,16 @@ func TestAppError(t *testing.T) {
 			},
 		}, nil)
 
-		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New())
+		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New(), metrics.Metrics{})
 
-		token, err := configurator.fetchCompassToken("compassID", "globalAccount")
+		token, err := configurator.fetchCompassToken(context.Background(), "compassID", "globalAccount")
 		require.NoError(t, err)
 		assert.Equal(t, "kyma.cloud.sap/connector/graphql", token.ConnectorURL)
 		assert.Equal(t, "dGVzdFRva2VuQmFzZWQ2NA==", token.Token)
 	})
 	t.Run("should return error after fetching invalid Connector URL", func(t *testing.T) {
 		mockDirectorClient := mocks.Client{}
-		mockDirectorClient.On("GetConnectionToken", "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
+		mockDirectorClient.On("GetConnectionToken", mock.Anything, "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
 			OneTimeTokenForRuntime: graphql.OneTimeTokenForRuntime{
 				TokenWithURL: graphql.TokenWithURL{
 					Token:        "dGVzdFRva2VuQmFzZWQ2NA==",
@@ -40,16 +44,16 @@ func TestAppError(t *testing.T) {
 			},
 		}, nil)
 
-		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New())
+		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New(), metrics.Metrics{})
 
-		token, err := configurator.fetchCompassToken("compassID", "globalAccount")
+		token, err := configurator.fetchCompassToken(context.Background(), "compassID", "globalAccount")
 		require.Error(t, err)
 		require.ErrorContains(t, err, "Connector URL does not match the expected pattern")
 		assert.Equal(t, token, graphql.OneTimeTokenForRuntimeExt{})
 	})
 	t.Run("should return error when Runtime Token is too long", func(t *testing.T) {
 		mockDirectorClient := mocks.Client{}
-		mockDirectorClient.On("GetConnectionToken", "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
+		mockDirectorClient.On("GetConnectionToken", mock.Anything, "compassID", "globalAccount").Return(graphql.OneTimeTokenForRuntimeExt{
 			OneTimeTokenForRuntime: graphql.OneTimeTokenForRuntime{
 				TokenWithURL: graphql.TokenWithURL{
 					Token:        "bm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVkbm90LWJhc2U2NC1lbmNvZGVk",
@@ -58,11 +62,70 @@ func TestAppError(t *testing.T) {
 			},
 		}, nil)
 
-		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New())
+		configurator := NewRuntimeAgentConfigurator(&mockDirectorClient, "kyma.cloud.sap/connector/graphql", logrus.New(), metrics.Metrics{})
 
-		token, err := configurator.fetchCompassToken("compassID", "globalAccount")
+		token, err := configurator.fetchCompassToken(context.Background(), "compassID", "globalAccount")
 		require.Error(t, err)
 		require.ErrorContains(t, err, "OneTimeToken is too long")
 		assert.Equal(t, token, graphql.OneTimeTokenForRuntimeExt{})
 	})
 }
+
+func TestAgentSecretDrift(t *testing.T) {
+	connectorURLPattern := "kyma.cloud.sap/connector/graphql"
+	validData := map[string][]byte{
+		"CONNECTOR_URL": []byte("https://compass.kyma.cloud.sap/connector/graphql"),
+		"RUNTIME_ID":    []byte("compassID"),
+		"TENANT":        []byte("globalAccount"),
+		"TOKEN":         []byte("dGVzdFRva2VuQmFzZWQ2NA=="),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(data map[string][]byte)
+		want   []string
+	}{
+		{
+			name:   "should report no drift for up to date secret",
+			modify: func(map[string][]byte) {},
+			want:   nil,
+		},
+		{
+			name: "should report no drift when one-time token was already used",
+			modify: func(data map[string][]byte) {
+				delete(data, "TOKEN")
+			},
+			want: nil,
+		},
+		{
+			name: "should report changed Runtime ID and Tenant",
+			modify: func(data map[string][]byte) {
+				data["RUNTIME_ID"] = []byte("otherID")
+				data["TENANT"] = []byte("otherAccount")
+			},
+			want: []string{"RUNTIME_ID", "TENANT"},
+		},
+		{
+			name: "should report Connector URL not matching the pattern",
+			modify: func(data map[string][]byte) {
+				data["CONNECTOR_URL"] = []byte("https://invalid.domain/connector/graphql")
+			},
+			want: []string{"CONNECTOR_URL"},
+		},
+		{
+			name: "should report all keys for empty secret",
+			modify: func(data map[string][]byte) {
+				clear(data)
+			},
+			want: []string{"RUNTIME_ID", "TENANT", "CONNECTOR_URL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := maps.Clone(validData)
+			tt.modify(data)
+
+			assert.Equal(t, tt.want, agentSecretDrift(data, "compassID", "globalAccount", connectorURLPattern))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+)
+
+const (
+	// LabelDirectorBackend is the label of the mapping with the Director backend the Runtime is registered in.
+	// Set as an annotation of the Kyma resource, it chooses the backend explicitly.
+	LabelDirectorBackend = "kyma-project.io/cm-director-backend"
+	LabelRegion          = "kyma-project.io/region"
+)
+
+// DirectorRouting chooses the Director backend the Runtime of the Kyma resource is registered in. The backend is taken,
+// in that order, from the annotation of the Kyma resource, the Global Account, the region of the Kyma resource,
+// and the default backend.
+type DirectorRouting struct {
+	DefaultBackend string            `json:"defaultBackend"`
+	GlobalAccounts map[string]string `json:"globalAccounts,omitempty"`
+	Regions        map[string]string `json:"regions,omitempty"`
+}
+
+// KymaBackend returns the backend for the Runtime of the Kyma resource according to the routing rules
+func (r DirectorRouting) KymaBackend(kymaCR kyma.Kyma) string {
+	if backend := kymaCR.Annotations[LabelDirectorBackend]; backend != "" {
+		return backend
+	}
+	if backend := r.GlobalAccounts[kymaCR.Labels[LabelGlobalAccountID]]; backend != "" {
+		return backend
+	}
+	if backend := r.Regions[kymaCR.Labels[LabelRegion]]; backend != "" {
+		return backend
+	}
+	return r.DefaultBackend
+}
+
+// MappingBackend returns the backend recorded on the mapping. Mappings recorded before the backends were introduced
+// belong to the default backend.
+func (r DirectorRouting) MappingBackend(mapping v1beta2.CompassManagerMapping) string {
+	if backend := mapping.Labels[LabelDirectorBackend]; backend != "" {
+		return backend
+	}
+	return r.DefaultBackend
+}
+
+// Backend returns the backend the Runtime of the mapping is registered in. Until the Runtime is registered,
+// the backend follows the routing rules, afterwards it stays the recorded one.
+func (r DirectorRouting) Backend(mapping v1beta2.CompassManagerMapping, kymaCR kyma.Kyma) string {
+	if mapping.Labels[LabelCompassID] != "" {
+		return r.MappingBackend(mapping)
+	}
+	return r.KymaBackend(kymaCR)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDirectorRouting(t *testing.T) {
+	routing := DirectorRouting{
+		DefaultBackend: "eu",
+		GlobalAccounts: map[string]string{"ga-us": "us"},
+		Regions:        map[string]string{"ap-southeast-1": "asia"},
+	}
+
+	newKyma := func(labels, annotations map[string]string) kyma.Kyma {
+		return kyma.Kyma{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations}}
+	}
+
+	for name, testCase := range map[string]struct {
+		kyma     kyma.Kyma
+		expected string
+	}{
+		"annotation takes precedence": {
+			kyma:     newKyma(map[string]string{LabelGlobalAccountID: "ga-us", LabelRegion: "ap-southeast-1"}, map[string]string{LabelDirectorBackend: "custom"}),
+			expected: "custom",
+		},
+		"Global Account takes precedence over region": {
+			kyma:     newKyma(map[string]string{LabelGlobalAccountID: "ga-us", LabelRegion: "ap-southeast-1"}, nil),
+			expected: "us",
+		},
+		"region": {
+			kyma:     newKyma(map[string]string{LabelGlobalAccountID: "ga", LabelRegion: "ap-southeast-1"}, nil),
+			expected: "asia",
+		},
+		"default": {
+			kyma:     newKyma(map[string]string{LabelGlobalAccountID: "ga", LabelRegion: "eu-central-1"}, nil),
+			expected: "eu",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, routing.KymaBackend(testCase.kyma))
+		})
+	}
+
+	t.Run("registered Runtime stays in the recorded backend", func(t *testing.T) {
+		mapping := v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelCompassID: "id", LabelDirectorBackend: "us"}}}
+
+		assert.Equal(t, "us", routing.Backend(mapping, newKyma(nil, map[string]string{LabelDirectorBackend: "asia"})))
+	})
+
+	t.Run("registered Runtime without recorded backend belongs to the default backend", func(t *testing.T) {
+		mapping := v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelCompassID: "id"}}}
+
+		assert.Equal(t, "eu", routing.Backend(mapping, newKyma(map[string]string{LabelGlobalAccountID: "ga-us"}, nil)))
+	})
+
+	t.Run("not registered Runtime follows the routing rules", func(t *testing.T) {
+		mapping := v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelDirectorBackend: "eu"}}}
+
+		assert.Equal(t, "us", routing.Backend(mapping, newKyma(map[string]string{LabelGlobalAccountID: "ga-us"}, nil)))
+	})
+}
@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"context"
+
 	"github.com/google/uuid"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/internal/logging"
 	"github.com/sirupsen/logrus"
 )
 
@@ -9,21 +13,51 @@ func NewDryRunner(log *logrus.Logger) *DryRunner {
 	return &DryRunner{log: log}
 }
 
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (dr DryRunner) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContextOr(ctx, dr.log)
+}
+
 type DryRunner struct {
 	log *logrus.Logger
 }
 
-func (dr DryRunner) ConfigureCompassRuntimeAgent(_ []byte, compassRuntimeID, globalAccount string) error {
-	dr.log.Infof("[DRY] Configure runtime %s for GA %s", compassRuntimeID, globalAccount)
+func (dr DryRunner) ConfigureCompassRuntimeAgent(ctx context.Context, _ []byte, compassRuntimeID, globalAccount string, _ v1beta2.AgentConfiguration) error {
+	dr.logger(ctx).Infof("[DRY] Configure runtime %s for GA %s", compassRuntimeID, globalAccount)
+	return nil
+}
+
+func (dr DryRunner) DeconfigureCompassRuntimeAgent(ctx context.Context, _ []byte, agentConfig v1beta2.AgentConfiguration) error {
+	dr.logger(ctx).Infof("[DRY] Remove Compass Runtime Agent configuration %s", agentSecretName(agentConfig))
 	return nil
 }
 
-func (dr DryRunner) RegisterInCompass(compassRuntimeLabels map[string]interface{}) (string, error) {
+func (dr DryRunner) VerifyCompassRuntimeAgent(ctx context.Context, _ []byte, compassRuntimeID, globalAccount string, _ v1beta2.AgentConfiguration) ([]string, error) {
+	dr.logger(ctx).Infof("[DRY] Verify configuration of runtime %s for GA %s", compassRuntimeID, globalAccount)
+	return nil, nil
+}
+
+func (dr DryRunner) RegisterInCompass(ctx context.Context, runtimeName string, compassRuntimeLabels map[string]interface{}) (string, error) {
 	compassID := uuid.New().String()
-	dr.log.Infof("[DRY] Register runtime %s: %s", compassRuntimeLabels["global_account_id"], compassID)
+	dr.logger(ctx).Infof("[DRY] Register runtime %s %s: %s", runtimeName, compassRuntimeLabels["global_account_id"], compassID)
 	return compassID, nil
 }
-func (dr DryRunner) DeregisterFromCompass(compassID, globalAccount string) error {
-	dr.log.Infof("[DRY] Register runtime, GA: %s Compass ID: %s", globalAccount, compassID)
+func (dr DryRunner) UpdateCompassRuntimeLabels(ctx context.Context, compassID, globalAccount string, _ map[string]interface{}) error {
+	dr.logger(ctx).Infof("[DRY] Update runtime labels, GA: %s Compass ID: %s", globalAccount, compassID)
 	return nil
 }
+
+func (dr DryRunner) FindInCompass(ctx context.Context, compassRuntimeLabels map[string]interface{}) (string, error) {
+	dr.logger(ctx).Infof("[DRY] Find runtime %s for GA %s", compassRuntimeLabels["gardenerClusterName"], compassRuntimeLabels["global_account_id"])
+	return "", nil
+}
+
+func (dr DryRunner) DeregisterFromCompass(ctx context.Context, compassID, globalAccount string) error {
+	dr.logger(ctx).Infof("[DRY] Register runtime, GA: %s Compass ID: %s", globalAccount, compassID)
+	return nil
+}
+
+func (dr DryRunner) IsRuntimeConnected(ctx context.Context, compassID, globalAccount string) (bool, error) {
+	dr.logger(ctx).Infof("[DRY] Check connection of runtime, GA: %s Compass ID: %s", globalAccount, compassID)
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	EventReasonMappingCreated           = "MappingCreated"
+	EventReasonMappingCreationFailed    = "MappingCreationFailed"
+	EventReasonRuntimeRegistered        = "RuntimeRegistered"
+	EventReasonRuntimeAdopted           = "RuntimeAdopted"
+	EventReasonRegistrationFailed       = "RuntimeRegistrationFailed"
+	EventReasonAgentConfigured          = "AgentConfigured"
+	EventReasonAgentConfigurationFailed = "AgentConfigurationFailed"
+	EventReasonAgentDeconfigured        = "AgentDeconfigured"
+	EventReasonRuntimeDeregistered      = "RuntimeDeregistered"
+	EventReasonDeregistrationFailed     = "RuntimeDeregistrationFailed"
+	EventReasonRuntimeMigrating         = "RuntimeMigrating"
+	EventReasonRuntimeMigrated          = "RuntimeMigrated"
+	EventReasonMigrationFailed          = "RuntimeMigrationFailed"
+	EventReasonMigrationCanceled        = "RuntimeMigrationCanceled"
+	EventReasonMigrationTimedOut        = "RuntimeMigrationTimedOut"
+	EventReasonRetrying                 = "Retrying"
+	EventReasonStalled                  = "Stalled"
+	EventReasonRuntimeRetained          = "RuntimeRetained"
+)
+
+// recordEvent records the event on the Compass Manager Mapping of the Kyma resource, which holds the status of the Runtime.
+// Until the mapping exists, and after it's deleted, the event is recorded on the Kyma resource.
+func (cm *CompassManagerReconciler) recordEvent(ctx context.Context, name types.NamespacedName, eventType, reason, messageFmt string, args ...interface{}) {
+	object := cm.eventObject(ctx, name)
+	if object == nil {
+		cm.logger(ctx).Debugf("Neither Compass Manager Mapping nor Kyma resource %s exists, skipping %s event", name.Name, reason)
+		return
+	}
+	cm.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// recordError records the Warning event for the failed operation, with the reason and the component of the apperrors.AppError wrapped in err
+func (cm *CompassManagerReconciler) recordError(ctx context.Context, name types.NamespacedName, reason, message string, err error) {
+	cm.recordEvent(ctx, name, corev1.EventTypeWarning, reason, "%s: %s", message, errorEventDetails(err))
+}
+
+func (cm *CompassManagerReconciler) eventObject(ctx context.Context, name types.NamespacedName) runtime.Object {
+	if mapping, err := cm.cluster.GetCompassMapping(ctx, name); err == nil {
+		return &mapping
+	}
+	if kymaCR, err := cm.cluster.GetKyma(ctx, name); err == nil {
+		return &kymaCR
+	}
+	return nil
+}
+
+// errorEventDetails describes the error together with the reason and the component of the apperrors.AppError wrapped in it
+func errorEventDetails(err error) string {
+	reason, component := apperrors.ErrCompassManagerInternal, apperrors.ErrCompassManager
+
+	var appErr apperrors.AppError
+	if errors.As(err, &appErr) {
+		component = appErr.Component()
+		if appErr.Reason() != "" {
+			reason = appErr.Reason()
+		}
+	}
+	return fmt.Sprintf("%s (reason: %s, component: %s)", logging.Redact(err.Error()), reason, component)
+}
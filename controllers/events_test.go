@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRecordEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kyma.AddToScheme(scheme))
+	require.NoError(t, v1beta2.AddToScheme(scheme))
+
+	name := types.NamespacedName{Name: "kyma", Namespace: "kcp-system"}
+	kymaCR := &kyma.Kyma{ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace}}
+	mapping := &v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{LabelKymaName: name.Name}}}
+
+	newReconciler := func(objects ...runtime.Object) (*CompassManagerReconciler, *record.FakeRecorder) {
+		kubectl := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+		recorder := record.NewFakeRecorder(10)
+		return &CompassManagerReconciler{
+			Log:      logrus.New(),
+			cluster:  NewControlPlaneInterface(kubectl, logrus.New(), false),
+			recorder: recorder,
+		}, recorder
+	}
+
+	t.Run("should record event on the mapping", func(t *testing.T) {
+		cm, recorder := newReconciler(kymaCR, mapping)
+
+		cm.recordEvent(context.Background(), name, corev1.EventTypeNormal, EventReasonRuntimeRegistered, "Runtime registered in Compass with ID %s", "id")
+
+		assert.Equal(t, "Normal RuntimeRegistered Runtime registered in Compass with ID id", <-recorder.Events)
+	})
+
+	t.Run("should record event on the Kyma resource when the mapping doesn't exist", func(t *testing.T) {
+		cm, recorder := newReconciler(kymaCR)
+
+		cm.recordError(context.Background(), name, EventReasonMappingCreationFailed, "Failed to create Compass Manager Mapping", errors.New("conflict"))
+
+		assert.Contains(t, <-recorder.Events, "Warning MappingCreationFailed Failed to create Compass Manager Mapping: conflict")
+	})
+
+	t.Run("should skip event when neither the mapping nor the Kyma resource exist", func(t *testing.T) {
+		cm, recorder := newReconciler()
+
+		cm.recordEvent(context.Background(), name, corev1.EventTypeNormal, EventReasonRuntimeDeregistered, "Runtime deregistered")
+
+		assert.Empty(t, recorder.Events)
+	})
+}
+
+func TestErrorEventDetails(t *testing.T) {
+	t.Run("should include reason and component of the application error", func(t *testing.T) {
+		err := errors.Wrap(apperrors.External("invalid client").SetReason(apperrors.ErrOAuthInvalidClient).SetComponent(apperrors.ErrMpsOAuth2), "failed to get token")
+
+		assert.Equal(t, "failed to get token: invalid client (reason: "+string(apperrors.ErrOAuthInvalidClient)+", component: "+string(apperrors.ErrMpsOAuth2)+")", errorEventDetails(err))
+	})
+
+	t.Run("should describe other errors as internal", func(t *testing.T) {
+		assert.Equal(t, "conflict (reason: "+string(apperrors.ErrCompassManagerInternal)+", component: "+string(apperrors.ErrCompassManager)+")", errorEventDetails(errors.New("conflict")))
+	})
+
+	t.Run("should redact secrets in the error", func(t *testing.T) {
+		err := errors.New(`graphql: unexpected response {"token":"one-time-token"}`)
+
+		assert.NotContains(t, errorEventDetails(err), "one-time-token")
+	})
+}
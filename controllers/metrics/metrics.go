@@ -1,73 +1,327 @@
 package metrics
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
 	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
-	MetricState   = "cm_states"
-	MetricActions = "cm_actions"
+	MetricState                   = "cm_states"
+	MetricActions                 = "cm_actions"
+	MetricOrphans                 = "cm_orphaned_runtimes"
+	MetricReconcileDuration       = "cm_reconcile_duration_seconds"
+	MetricTimeToRegistered        = "cm_time_to_registered_seconds"
+	MetricTimeToConfigured        = "cm_time_to_configured_seconds"
+	MetricDirectorRequestDuration = "cm_director_request_duration_seconds"
+	MetricDirectorRequestErrors   = "cm_director_request_errors_total"
+	MetricTokenRequestDuration    = "cm_oauth_token_request_duration_seconds"
+	MetricTokenRequestFailures    = "cm_oauth_token_request_failures_total"
+	MetricRuntimeRequestDuration  = "cm_runtime_api_request_duration_seconds"
+
+	LabelState         = "state"
+	LabelName          = "kyma_name"
+	LabelAction        = "action"
+	LabelGlobalAccount = "global_account_id"
+	LabelResult        = "result"
+	LabelOperation     = "operation"
+	LabelReason        = "reason"
+	LabelMethod        = "method"
+	LabelCode          = "code"
+	LabelPlanName      = "plan_name"
+	LabelBackend       = "backend"
+
+	ActionRegister       = "register"
+	ActionAdopt          = "adopt"
+	ActionConfigure      = "configure"
+	ActionUnregister     = "unregister"
+	ActionDriftCorrected = "drift_corrected"
 
-	LabelState  = "state"
-	LabelName   = "kyma_name"
-	LabelAction = "action"
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Mode chooses the labels of the state and action metrics
+type Mode string
 
-	ActionRegister   = "register"
-	ActionConfigure  = "configure"
-	ActionUnregister = "unregister"
+const (
+	// ModePerKyma labels the metrics with the name of the Kyma resource. The series of the Kyma resource are deleted with its mapping.
+	ModePerKyma Mode = "per-kyma"
+	// ModeAggregate labels the metrics with the plan and the Global Account only, so the number of series doesn't grow with the number of Kyma resources.
+	// The state metric counts the Kyma resources in each state.
+	ModeAggregate Mode = "aggregate"
 )
 
+// Runtime identifies the Kyma runtime in the metrics
+type Runtime struct {
+	KymaName      string
+	PlanName      string
+	GlobalAccount string
+}
+
+// stateKey is the series of the state metric the Kyma runtime is counted in, in the aggregate mode
+type stateKey struct {
+	state         string
+	planName      string
+	globalAccount string
+}
+
+// aggregateStates remembers the series each Kyma runtime is counted in, so that it's moved out of it when the state changes
+type aggregateStates struct {
+	mu       sync.Mutex
+	runtimes map[string]stateKey
+}
+
+// orphanedGlobalAccounts remembers the Global Accounts of the last orphaned Runtime collection, so that the series of the ones
+// that are gone are deleted
+type orphanedGlobalAccounts struct {
+	mu             sync.Mutex
+	globalAccounts map[string]bool
+}
+
+// onboardingBuckets cover the time from a few seconds up to several hours it may take to register and configure the Runtime
+var onboardingBuckets = prometheus.ExponentialBuckets(5, 2, 12) //nolint:gochecknoglobals,mnd
+
 type Metrics struct {
-	states  *prometheus.GaugeVec
-	actions *prometheus.CounterVec
+	mode       Mode
+	states     *prometheus.GaugeVec
+	actions    *prometheus.CounterVec
+	orphans    *prometheus.GaugeVec
+	aggregated *aggregateStates
+	orphanedIn *orphanedGlobalAccounts
+
+	reconcileDuration       *prometheus.HistogramVec
+	timeToRegistered        prometheus.Histogram
+	timeToConfigured        prometheus.Histogram
+	directorRequestDuration *prometheus.HistogramVec
+	directorRequestErrors   *prometheus.CounterVec
+	tokenRequestDuration    *prometheus.HistogramVec
+	tokenRequestFailures    *prometheus.CounterVec
+	runtimeRequestDuration  *prometheus.HistogramVec
+}
+
+// NewMetrics creates the metrics and registers them in the controller-runtime registry. The state and action metrics are labelled according to the mode.
+func NewMetrics(mode Mode) (Metrics, error) {
+	m, err := newMetrics(mode)
+	if err != nil {
+		return Metrics{}, err
+	}
+	metrics.Registry.MustRegister(m.collectors()...)
+	return m, nil
 }
 
-func NewMetrics() Metrics {
+func newMetrics(mode Mode) (Metrics, error) {
+	stateLabels := []string{LabelName, LabelState}
+	actionLabels := []string{LabelName, LabelAction}
+	switch mode {
+	case ModePerKyma:
+	case ModeAggregate:
+		stateLabels = []string{LabelState, LabelPlanName, LabelGlobalAccount}
+		actionLabels = []string{LabelAction, LabelPlanName, LabelGlobalAccount}
+	default:
+		return Metrics{}, fmt.Errorf("unknown metrics mode %q, expected %q or %q", mode, ModePerKyma, ModeAggregate)
+	}
+
 	m := Metrics{
+		mode:       mode,
+		aggregated: &aggregateStates{runtimes: make(map[string]stateKey)},
+		orphanedIn: &orphanedGlobalAccounts{globalAccounts: make(map[string]bool)},
+
 		states: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: MetricState,
-			Help: "Indicates the Status.state for Compass Mappings",
-		}, []string{LabelName, LabelState}),
+			Help: "Indicates the Status.state for Compass Mappings, or the number of Compass Mappings in the state in the aggregate mode",
+		}, stateLabels),
 
 		actions: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: MetricActions,
 			Help: "Number of <action> performed on Kymas",
-		}, []string{LabelName, LabelAction}),
+		}, actionLabels),
+
+		orphans: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricOrphans,
+			Help: "Number of Runtimes managed by Compass Manager in Compass without Compass Mapping and Kyma",
+		}, []string{LabelGlobalAccount}),
+
+		reconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricReconcileDuration,
+			Help:    "Duration of the reconciliation of Kyma resources",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelResult}),
+
+		timeToRegistered: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    MetricTimeToRegistered,
+			Help:    "Time from the creation of the Compass Mapping until the Runtime was registered in Compass",
+			Buckets: onboardingBuckets,
+		}),
+
+		timeToConfigured: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    MetricTimeToConfigured,
+			Help:    "Time from the creation of the Compass Mapping until the Compass Runtime Agent was configured for the first time",
+			Buckets: onboardingBuckets,
+		}),
+
+		directorRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricDirectorRequestDuration,
+			Help:    "Duration of the Director GraphQL operations, including the retry with a new token",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelBackend, LabelOperation}),
+
+		directorRequestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricDirectorRequestErrors,
+			Help: "Number of failed Director GraphQL operations",
+		}, []string{LabelBackend, LabelOperation, LabelReason}),
+
+		tokenRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricTokenRequestDuration,
+			Help:    "Duration of the requests for the OAuth token to access Director",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelResult}),
+
+		tokenRequestFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricTokenRequestFailures,
+			Help: "Number of failed requests for the OAuth token to access Director",
+		}, []string{LabelReason}),
+
+		runtimeRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricRuntimeRequestDuration,
+			Help:    "Duration of the requests to the API servers of Runtimes, sent to configure the Compass Runtime Agent",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelMethod, LabelCode}),
 	}
-	metrics.Registry.MustRegister(m.states, m.actions)
-	return m
+	return m, nil
 }
 
-func (m Metrics) IncConfigure(kymaName string) {
-	m.actions.With(prometheus.Labels{
-		LabelName:   kymaName,
-		LabelAction: ActionConfigure,
-	}).Inc()
+func (m Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.states, m.actions, m.orphans,
+		m.reconcileDuration, m.timeToRegistered, m.timeToConfigured,
+		m.directorRequestDuration, m.directorRequestErrors,
+		m.tokenRequestDuration, m.tokenRequestFailures,
+		m.runtimeRequestDuration}
 }
 
-func (m Metrics) IncRegister(kymaName string) {
-	m.actions.With(prometheus.Labels{
-		LabelName:   kymaName,
-		LabelAction: ActionRegister,
-	}).Inc()
+func (m Metrics) IncConfigure(runtime Runtime) {
+	m.incAction(runtime, ActionConfigure)
 }
 
-func (m Metrics) IncUnregister(kymaName string) {
+func (m Metrics) IncRegister(runtime Runtime) {
+	m.incAction(runtime, ActionRegister)
+}
+
+func (m Metrics) IncAdopt(runtime Runtime) {
+	m.incAction(runtime, ActionAdopt)
+}
+
+func (m Metrics) IncUnregister(runtime Runtime) {
+	m.incAction(runtime, ActionUnregister)
+}
+
+func (m Metrics) IncDriftCorrected(runtime Runtime) {
+	m.incAction(runtime, ActionDriftCorrected)
+}
+
+func (m Metrics) incAction(runtime Runtime, action string) {
+	if m.mode == ModeAggregate {
+		m.actions.With(prometheus.Labels{
+			LabelAction:        action,
+			LabelPlanName:      runtime.PlanName,
+			LabelGlobalAccount: runtime.GlobalAccount,
+		}).Inc()
+		return
+	}
+
 	m.actions.With(prometheus.Labels{
-		LabelName:   kymaName,
-		LabelAction: ActionUnregister,
+		LabelName:   runtime.KymaName,
+		LabelAction: action,
 	}).Inc()
 }
 
-func (m Metrics) UpdateState(kymaName string, status s.Status) {
+// SetOrphanedRuntimes sets the number of orphaned Runtimes in each Global Account found by the collection,
+// and deletes the series of the Global Accounts the previous collection found, but this one didn't
+func (m Metrics) SetOrphanedRuntimes(counts map[string]int) {
+	m.orphanedIn.mu.Lock()
+	defer m.orphanedIn.mu.Unlock()
+
+	for globalAccount := range m.orphanedIn.globalAccounts {
+		if _, ok := counts[globalAccount]; !ok {
+			m.orphans.Delete(prometheus.Labels{LabelGlobalAccount: globalAccount})
+			delete(m.orphanedIn.globalAccounts, globalAccount)
+		}
+	}
+	for globalAccount, count := range counts {
+		m.orphans.With(prometheus.Labels{
+			LabelGlobalAccount: globalAccount,
+		}).Set(float64(count))
+		m.orphanedIn.globalAccounts[globalAccount] = true
+	}
+}
+
+// UpdateState sets the state of the Kyma runtime. The Empty status removes the runtime from the state metric.
+func (m Metrics) UpdateState(runtime Runtime, status s.Status) {
 	if status == s.Empty {
-		m.setModuleStateGauge(kymaName, "")
+		m.deleteState(runtime.KymaName)
 		return
 	}
 	state := s.StateText(status)
-	m.setModuleStateGauge(kymaName, state)
+
+	if m.mode == ModeAggregate {
+		m.moveAggregateState(runtime.KymaName, stateKey{state: state, planName: runtime.PlanName, globalAccount: runtime.GlobalAccount})
+		return
+	}
+	m.setModuleStateGauge(runtime.KymaName, state)
+}
+
+// DeleteRuntime removes the series of the Kyma runtime, after its mapping was deleted.
+// In the aggregate mode, the runtime is no longer counted in the state metric.
+func (m Metrics) DeleteRuntime(kymaName string) {
+	m.deleteState(kymaName)
+	if m.mode == ModePerKyma {
+		m.actions.DeletePartialMatch(prometheus.Labels{LabelName: kymaName})
+	}
+}
+
+func (m Metrics) deleteState(kymaName string) {
+	if m.mode == ModeAggregate {
+		m.moveAggregateState(kymaName, stateKey{})
+		return
+	}
+	m.states.DeletePartialMatch(prometheus.Labels{LabelName: kymaName})
+}
+
+// moveAggregateState moves the Kyma runtime from the series it was counted in to the new one. The empty key removes the runtime.
+func (m Metrics) moveAggregateState(kymaName string, key stateKey) {
+	m.aggregated.mu.Lock()
+	defer m.aggregated.mu.Unlock()
+
+	previous, ok := m.aggregated.runtimes[kymaName]
+	if ok && previous == key {
+		return
+	}
+	if ok {
+		m.states.With(previous.labels()).Dec()
+		delete(m.aggregated.runtimes, kymaName)
+	}
+	if key != (stateKey{}) {
+		m.states.With(key.labels()).Inc()
+		m.aggregated.runtimes[kymaName] = key
+	}
+}
+
+func (k stateKey) labels() prometheus.Labels {
+	return prometheus.Labels{
+		LabelState:         k.state,
+		LabelPlanName:      k.planName,
+		LabelGlobalAccount: k.globalAccount,
+	}
 }
 
 func (m Metrics) setModuleStateGauge(kymaName, state string) {
@@ -82,3 +336,67 @@ func (m Metrics) setModuleStateGauge(kymaName, state string) {
 		}).Set(val)
 	}
 }
+
+func (m Metrics) ObserveReconcile(duration time.Duration, err error) {
+	m.reconcileDuration.With(prometheus.Labels{
+		LabelResult: result(err),
+	}).Observe(duration.Seconds())
+}
+
+func (m Metrics) ObserveTimeToRegistered(duration time.Duration) {
+	m.timeToRegistered.Observe(duration.Seconds())
+}
+
+func (m Metrics) ObserveTimeToConfigured(duration time.Duration) {
+	m.timeToConfigured.Observe(duration.Seconds())
+}
+
+// ObserveDirectorRequest implements director.RequestObserver, the operations are labeled with the Director backend they were routed to
+func (m Metrics) ObserveDirectorRequest(ctx context.Context, operation string, duration time.Duration, err apperrors.AppError) {
+	backend := director.BackendFromContext(ctx)
+	m.directorRequestDuration.With(prometheus.Labels{
+		LabelBackend:   backend,
+		LabelOperation: operation,
+	}).Observe(duration.Seconds())
+
+	if err != nil {
+		m.directorRequestErrors.With(prometheus.Labels{
+			LabelBackend:   backend,
+			LabelOperation: operation,
+			LabelReason:    reason(err),
+		}).Inc()
+	}
+}
+
+// ObserveTokenRequest implements oauth.TokenObserver
+func (m Metrics) ObserveTokenRequest(duration time.Duration, err apperrors.AppError) {
+	m.tokenRequestDuration.With(prometheus.Labels{
+		LabelResult: result(err),
+	}).Observe(duration.Seconds())
+
+	if err != nil {
+		m.tokenRequestFailures.With(prometheus.Labels{
+			LabelReason: reason(err),
+		}).Inc()
+	}
+}
+
+// InstrumentRuntimeAPI wraps the transport of the Runtime API client to observe the duration of its requests
+func (m Metrics) InstrumentRuntimeAPI(transport http.RoundTripper) http.RoundTripper {
+	return promhttp.InstrumentRoundTripperDuration(m.runtimeRequestDuration, transport)
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+// reason returns the reason of the AppError, errors without the reason are reported with the reason of their code
+func reason(err apperrors.AppError) string {
+	if err.Reason() != "" {
+		return string(err.Reason())
+	}
+	return "code_" + strconv.Itoa(int(err.Code()))
+}
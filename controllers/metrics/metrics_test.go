@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m, err := newMetrics(ModePerKyma)
+	require.NoError(t, err)
+
+	t.Run("should count Director errors by backend, operation and reason", func(t *testing.T) {
+		eu := director.ContextWithBackend(context.Background(), "eu")
+		us := director.ContextWithBackend(context.Background(), "us")
+		m.ObserveDirectorRequest(eu, director.OperationGetRuntime, time.Second, nil)
+		m.ObserveDirectorRequest(eu, director.OperationGetRuntime, time.Second, apperrors.NotFound("not found").SetReason(apperrors.ErrDirectorRuntimeNotFound))
+		m.ObserveDirectorRequest(us, director.OperationGetRuntime, time.Second, apperrors.Internal("failed"))
+
+		assert.Equal(t, 2, testutil.CollectAndCount(m.directorRequestDuration))
+		assert.InDelta(t, 1, testutil.ToFloat64(m.directorRequestErrors.WithLabelValues("eu", director.OperationGetRuntime, string(apperrors.ErrDirectorRuntimeNotFound))), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.directorRequestErrors.WithLabelValues("us", director.OperationGetRuntime, string(apperrors.ErrCompassManagerInternal))), 0)
+	})
+
+	t.Run("should count token request failures by reason", func(t *testing.T) {
+		m.ObserveTokenRequest(time.Second, nil)
+		m.ObserveTokenRequest(time.Second, apperrors.External("invalid client").SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthInvalidClient))
+
+		assert.Equal(t, 2, testutil.CollectAndCount(m.tokenRequestDuration))
+		assert.InDelta(t, 1, testutil.ToFloat64(m.tokenRequestFailures.WithLabelValues(string(apperrors.ErrOAuthInvalidClient))), 0)
+	})
+
+	t.Run("should observe Runtime API requests by method and code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: m.InstrumentRuntimeAPI(http.DefaultTransport)}
+		resp, err := client.Get(server.URL) //nolint:noctx
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, 1, testutil.CollectAndCount(m.runtimeRequestDuration))
+		assert.Contains(t, metricLabels(t, m.runtimeRequestDuration), map[string]string{LabelMethod: "get", LabelCode: "404"})
+	})
+}
+
+func TestStateMetrics(t *testing.T) {
+	t.Run("should delete series of the Kyma runtime in the per-Kyma mode", func(t *testing.T) {
+		m, err := newMetrics(ModePerKyma)
+		require.NoError(t, err)
+
+		m.UpdateState(Runtime{KymaName: "kyma-1"}, s.Registered|s.Configured)
+		m.UpdateState(Runtime{KymaName: "kyma-2"}, s.Registered|s.Processing)
+		m.IncRegister(Runtime{KymaName: "kyma-1"})
+		m.IncRegister(Runtime{KymaName: "kyma-2"})
+
+		m.DeleteRuntime("kyma-1")
+
+		assert.Equal(t, 3, testutil.CollectAndCount(m.states))
+		assert.InDelta(t, 1, testutil.ToFloat64(m.states.WithLabelValues("kyma-2", s.ProcessingState)), 0)
+		assert.Equal(t, []map[string]string{{LabelName: "kyma-2", LabelAction: ActionRegister}}, metricLabels(t, m.actions))
+	})
+
+	t.Run("should delete state series of the Kyma runtime with the Empty status", func(t *testing.T) {
+		m, err := newMetrics(ModePerKyma)
+		require.NoError(t, err)
+
+		m.UpdateState(Runtime{KymaName: "kyma"}, s.Registered|s.Configured)
+		m.UpdateState(Runtime{KymaName: "kyma"}, s.Empty)
+
+		assert.Equal(t, 0, testutil.CollectAndCount(m.states))
+	})
+
+	t.Run("should count Kyma runtimes in each state in the aggregate mode", func(t *testing.T) {
+		m, err := newMetrics(ModeAggregate)
+		require.NoError(t, err)
+		runtime1 := Runtime{KymaName: "kyma-1", PlanName: "aws", GlobalAccount: "ga"}
+		runtime2 := Runtime{KymaName: "kyma-2", PlanName: "aws", GlobalAccount: "ga"}
+
+		m.UpdateState(runtime1, s.Registered|s.Processing)
+		m.UpdateState(runtime2, s.Registered|s.Processing)
+		m.UpdateState(runtime1, s.Registered|s.Configured)
+		m.UpdateState(runtime1, s.Registered|s.Configured)
+		m.IncConfigure(runtime1)
+		m.IncConfigure(runtime2)
+
+		assert.InDelta(t, 1, testutil.ToFloat64(m.states.WithLabelValues(s.ReadyState, "aws", "ga")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.states.WithLabelValues(s.ProcessingState, "aws", "ga")), 0)
+		assert.InDelta(t, 2, testutil.ToFloat64(m.actions.WithLabelValues(ActionConfigure, "aws", "ga")), 0)
+
+		m.DeleteRuntime("kyma-1")
+		m.DeleteRuntime("kyma-1")
+
+		assert.InDelta(t, 0, testutil.ToFloat64(m.states.WithLabelValues(s.ReadyState, "aws", "ga")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(m.states.WithLabelValues(s.ProcessingState, "aws", "ga")), 0)
+	})
+
+	t.Run("should delete orphaned Runtime series of Global Accounts no longer collected", func(t *testing.T) {
+		m, err := newMetrics(ModePerKyma)
+		require.NoError(t, err)
+
+		m.SetOrphanedRuntimes(map[string]int{"ga-1": 2, "ga-2": 1})
+		m.SetOrphanedRuntimes(map[string]int{"ga-2": 0})
+
+		assert.Equal(t, []map[string]string{{LabelGlobalAccount: "ga-2"}}, metricLabels(t, m.orphans))
+		assert.InDelta(t, 0, testutil.ToFloat64(m.orphans.WithLabelValues("ga-2")), 0)
+	})
+
+	t.Run("should reject unknown mode", func(t *testing.T) {
+		_, err := newMetrics("per-plan")
+		require.Error(t, err)
+	})
+}
+
+func metricLabels(t *testing.T, collector prometheus.Collector) []map[string]string {
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var labels []map[string]string
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			pairs := make(map[string]string)
+			for _, pair := range metric.GetLabel() {
+				pairs[pair.GetName()] = pair.GetValue()
+			}
+			labels = append(labels, pairs)
+		}
+	}
+	return labels
+}
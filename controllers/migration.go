@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/director"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationMigrationTargetBackend on the mapping requests the migration of the Runtime to another Director backend
+	AnnotationMigrationTargetBackend = "kyma-project.io/cm-migration-target-backend"
+	// AnnotationMigrationTargetGlobalAccount on the mapping requests the migration of the Runtime to another Global Account
+	AnnotationMigrationTargetGlobalAccount = "kyma-project.io/cm-migration-target-global-account"
+	// AnnotationMigrationRuntimeID is the ID of the Runtime registered in the migration target, set while the migration is in progress
+	AnnotationMigrationRuntimeID = "kyma-project.io/cm-migration-runtime-id"
+	// AnnotationMigrationStartedBackend is the Director backend of the migration in progress, set when the migration starts
+	AnnotationMigrationStartedBackend = "kyma-project.io/cm-migration-started-backend"
+	// AnnotationMigrationStartedGlobalAccount is the Global Account of the migration in progress, set when the migration starts
+	AnnotationMigrationStartedGlobalAccount = "kyma-project.io/cm-migration-started-global-account"
+	// AnnotationMigrationStartTime is the time the migration in progress started, in RFC 3339 format
+	AnnotationMigrationStartTime = "kyma-project.io/cm-migration-start-time"
+)
+
+// runtimeLocation is the Director backend and the Global Account a Runtime is registered in
+type runtimeLocation struct {
+	backend       string
+	globalAccount string
+}
+
+func (l runtimeLocation) String() string {
+	return fmt.Sprintf("Global Account %s in Compass %s", l.globalAccount, l.backend)
+}
+
+// isMigrationRequested returns true if the mapping has any of the migration target annotations
+func isMigrationRequested(mapping v1beta2.CompassManagerMapping) bool {
+	return mapping.Annotations[AnnotationMigrationTargetBackend] != "" || mapping.Annotations[AnnotationMigrationTargetGlobalAccount] != ""
+}
+
+// isMigrationStarted returns true if the mapping has the annotations of the migration in progress
+func isMigrationStarted(mapping v1beta2.CompassManagerMapping) bool {
+	_, started := startedMigrationTarget(mapping)
+	return started
+}
+
+// startedMigrationTarget returns the target of the migration in progress, which may differ from the requested one if the annotations were changed since
+func startedMigrationTarget(mapping v1beta2.CompassManagerMapping) (runtimeLocation, bool) {
+	target := runtimeLocation{
+		backend:       mapping.Annotations[AnnotationMigrationStartedBackend],
+		globalAccount: mapping.Annotations[AnnotationMigrationStartedGlobalAccount],
+	}
+	return target, target.backend != "" || target.globalAccount != ""
+}
+
+// migrationRequestChanged returns true if the update of the mapping requests the migration, changes its target, or cancels the migration in progress
+func migrationRequestChanged(oldObj, newObj client.Object) bool {
+	oldAnnotations, newAnnotations := oldObj.GetAnnotations(), newObj.GetAnnotations()
+	if newAnnotations[AnnotationMigrationTargetBackend] == "" && newAnnotations[AnnotationMigrationTargetGlobalAccount] == "" &&
+		newAnnotations[AnnotationMigrationStartedBackend] == "" && newAnnotations[AnnotationMigrationStartedGlobalAccount] == "" {
+		return false
+	}
+	return oldAnnotations[AnnotationMigrationTargetBackend] != newAnnotations[AnnotationMigrationTargetBackend] ||
+		oldAnnotations[AnnotationMigrationTargetGlobalAccount] != newAnnotations[AnnotationMigrationTargetGlobalAccount]
+}
+
+// migrationTarget returns the location the Runtime is migrated to, taking the values not set in the annotations from the source.
+// It returns false if the target is the same as the source.
+func migrationTarget(mapping v1beta2.CompassManagerMapping, source runtimeLocation) (runtimeLocation, bool) {
+	target := source
+	if backend := mapping.Annotations[AnnotationMigrationTargetBackend]; backend != "" {
+		target.backend = backend
+	}
+	if globalAccount := mapping.Annotations[AnnotationMigrationTargetGlobalAccount]; globalAccount != "" {
+		target.globalAccount = globalAccount
+	}
+	return target, target != source
+}
+
+// migrateRuntime moves the registered Runtime to the target location, one step per reconciliation: it registers the Runtime in the target,
+// configures the Compass Runtime Agent for it, waits until the agent connects to the target, and only then deregisters the source Runtime
+// and updates the mapping. Failed steps are retried with the backoff, and the source Runtime is kept until the migration completes.
+func (cm *CompassManagerReconciler) migrateRuntime(ctx context.Context, name types.NamespacedName, kymaCR kyma.Kyma, mapping v1beta2.CompassManagerMapping,
+	kubeconfig []byte, source, target runtimeLocation) (ctrl.Result, error) {
+	targetCtx := director.ContextWithBackend(ctx, target.backend)
+	targetRuntimeID := mapping.Annotations[AnnotationMigrationRuntimeID]
+
+	if targetRuntimeID == "" {
+		cm.logger(ctx).Infof("Migrating Runtime of Kyma resource %s from %s to %s", name.Name, source, target)
+
+		compassRuntimeLabels := mappingCompassRuntimeLabels(kymaCR.Labels, mapping.Spec)
+		compassRuntimeLabels["global_account_id"] = target.globalAccount
+		runtimeID, err := cm.Registrator.RegisterInCompass(targetCtx, mapping.Spec.RuntimeName, compassRuntimeLabels)
+		if err != nil {
+			return cm.migrationFailed(ctx, name, errors.Wrapf(err, "failed to register Runtime in %s", target))
+		}
+
+		if err := cm.cluster.SetCompassMappingAnnotations(ctx, name, map[string]string{AnnotationMigrationRuntimeID: runtimeID}); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to record migrated Runtime ID for Kyma resource %s", name.Name)
+		}
+		return cm.migrationProgressed(ctx, name, fmt.Sprintf("Runtime registered in %s with ID %s", target, runtimeID))
+	}
+
+	drift, err := cm.Configurator.VerifyCompassRuntimeAgent(targetCtx, kubeconfig, targetRuntimeID, target.globalAccount, mapping.Spec.AgentConfiguration)
+	if err != nil {
+		return cm.migrationFailed(ctx, name, errors.Wrap(err, "failed to verify Compass Runtime Agent configuration"))
+	}
+	if len(drift) > 0 {
+		if err := cm.Configurator.ConfigureCompassRuntimeAgent(targetCtx, kubeconfig, targetRuntimeID, target.globalAccount, mapping.Spec.AgentConfiguration); err != nil {
+			return cm.migrationFailed(ctx, name, errors.Wrapf(err, "failed to configure Compass Runtime Agent for Runtime %s", targetRuntimeID))
+		}
+		return cm.migrationProgressed(ctx, name, fmt.Sprintf("Compass Runtime Agent configured for Runtime %s, waiting for the connection", targetRuntimeID))
+	}
+
+	connected, err := cm.Registrator.IsRuntimeConnected(targetCtx, targetRuntimeID, target.globalAccount)
+	if err != nil {
+		return cm.migrationFailed(ctx, name, errors.Wrapf(err, "failed to verify connection of Runtime %s", targetRuntimeID))
+	}
+	if !connected {
+		return cm.migrationWaiting(ctx, name, mapping, fmt.Sprintf("Waiting for Compass Runtime Agent to connect to Runtime %s in %s", targetRuntimeID, target))
+	}
+
+	sourceRuntimeID := mapping.Labels[LabelCompassID]
+	err = cm.Registrator.DeregisterFromCompass(director.ContextWithBackend(ctx, source.backend), sourceRuntimeID, source.globalAccount)
+	if err != nil && !isRuntimeNotFound(err) {
+		return cm.migrationFailed(ctx, name, errors.Wrapf(err, "failed to deregister Runtime %s from %s", sourceRuntimeID, source))
+	}
+
+	if err := cm.cluster.CompleteCompassMappingMigration(ctx, name, targetRuntimeID, target.backend, target.globalAccount); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to update Compass Manager Mapping after migration of Kyma resource %s", name.Name)
+	}
+	cm.backoff.Forget(name)
+	cm.logger(ctx).Infof("Runtime of Kyma resource %s migrated from %s to %s", name.Name, source, target)
+	cm.recordEvent(ctx, name, corev1.EventTypeNormal, EventReasonRuntimeMigrated, "Runtime %s migrated to %s as %s", sourceRuntimeID, target, targetRuntimeID)
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name,
+		s.ConditionTrue(v1beta2.ConditionTypeMigrated, s.ReasonMigrated, fmt.Sprintf("Runtime %s migrated to %s as %s", sourceRuntimeID, target, targetRuntimeID)),
+		s.ConditionTrue(v1beta2.ConditionTypeRuntimeRegistered, s.ReasonRegistered, "Runtime registered with ID "+targetRuntimeID),
+		s.ConditionTrue(v1beta2.ConditionTypeDirectorReachable, s.ReasonDirectorResponded, ""))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions after migration")
+	}
+	return ctrl.Result{}, nil
+}
+
+// startMigration records the target of the requested migration, and clears the Stalled condition left by the previous attempts,
+// so that the migration to the new target is retried
+func (cm *CompassManagerReconciler) startMigration(ctx context.Context, name types.NamespacedName, target runtimeLocation) (ctrl.Result, error) {
+	annotations := map[string]string{
+		AnnotationMigrationStartedBackend:       target.backend,
+		AnnotationMigrationStartedGlobalAccount: target.globalAccount,
+		AnnotationMigrationStartTime:            time.Now().UTC().Format(time.RFC3339),
+		AnnotationMigrationRuntimeID:            "",
+	}
+	if err := cm.cluster.SetCompassMappingAnnotations(ctx, name, annotations); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to record migration target for Kyma resource %s", name.Name)
+	}
+	if err := cm.cluster.SetCompassMappingConditions(ctx, name, s.ConditionFalse(v1beta2.ConditionTypeStalled, s.ReasonRetrying, "")); err != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(err, "failed to set Compass Manager Mapping conditions at the start of migration")
+	}
+	return cm.migrationProgressed(ctx, name, fmt.Sprintf("Migration to %s started", target))
+}
+
+// retargetMigration handles the change of the target annotations while the migration is in progress. The Runtime registered in the previous target
+// is deregistered, and the migration starts over with the new target, or is canceled if the annotations were removed.
+func (cm *CompassManagerReconciler) retargetMigration(ctx context.Context, name types.NamespacedName, mapping v1beta2.CompassManagerMapping,
+	previous, target runtimeLocation, migrate bool) (ctrl.Result, error) {
+	if runtimeID := mapping.Annotations[AnnotationMigrationRuntimeID]; runtimeID != "" {
+		err := cm.Registrator.DeregisterFromCompass(director.ContextWithBackend(ctx, previous.backend), runtimeID, previous.globalAccount)
+		if err != nil && !isRuntimeNotFound(err) {
+			return cm.migrationFailed(ctx, name, errors.Wrapf(err, "failed to deregister Runtime %s from the previous migration target %s", runtimeID, previous))
+		}
+		cm.logger(ctx).Infof("Runtime %s registered in the previous migration target %s deregistered", runtimeID, previous)
+	}
+
+	if migrate {
+		cm.logger(ctx).Infof("Migration target of Kyma resource %s changed from %s to %s", name.Name, previous, target)
+		return cm.startMigration(ctx, name, target)
+	}
+
+	annotations := map[string]string{
+		AnnotationMigrationStartedBackend:       "",
+		AnnotationMigrationStartedGlobalAccount: "",
+		AnnotationMigrationStartTime:            "",
+		AnnotationMigrationRuntimeID:            "",
+	}
+	if err := cm.cluster.SetCompassMappingAnnotations(ctx, name, annotations); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to remove migration annotations for Kyma resource %s", name.Name)
+	}
+	cm.backoff.Forget(name)
+	cm.logger(ctx).Infof("Migration of Kyma resource %s to %s canceled", name.Name, previous)
+	cm.recordEvent(ctx, name, corev1.EventTypeNormal, EventReasonMigrationCanceled, "Migration to %s canceled", previous)
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name,
+		s.ConditionFalse(v1beta2.ConditionTypeMigrated, s.ReasonMigrationCanceled, fmt.Sprintf("Migration to %s canceled", previous)),
+		s.ConditionFalse(v1beta2.ConditionTypeStalled, s.ReasonRetrying, ""))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions after canceled migration")
+	}
+	return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
+}
+
+// deregisterMigrationRuntime deregisters the Runtime registered in the target of the migration in progress,
+// so that it isn't left behind in Compass when the source Runtime is deregistered before the migration completes
+func (cm *CompassManagerReconciler) deregisterMigrationRuntime(ctx context.Context, mapping v1beta2.CompassManagerMapping, source runtimeLocation) error {
+	runtimeID := mapping.Annotations[AnnotationMigrationRuntimeID]
+	if runtimeID == "" {
+		return nil
+	}
+	target, started := startedMigrationTarget(mapping)
+	if !started {
+		target, _ = migrationTarget(mapping, source)
+	}
+
+	cm.logger(ctx).Infof("Deregistering Runtime %s registered in the migration target %s", runtimeID, target)
+	err := cm.Registrator.DeregisterFromCompass(director.ContextWithBackend(ctx, target.backend), runtimeID, target.globalAccount)
+	if err != nil && !isRuntimeNotFound(err) {
+		cm.logger(ctx).Warnf("Failed to deregister Runtime %s from the migration target %s: %v", runtimeID, target, err)
+		cm.recorder.Eventf(&mapping, corev1.EventTypeWarning, EventReasonDeregistrationFailed,
+			"Failed to deregister Runtime %s from the migration target %s: %s", runtimeID, target, errorEventDetails(err))
+		return errors.Wrap(&DirectorError{message: err}, "failed to deregister Runtime from the migration target")
+	}
+	cm.recorder.Eventf(&mapping, corev1.EventTypeNormal, EventReasonRuntimeDeregistered, "Runtime %s deregistered from the migration target %s", runtimeID, target)
+	return nil
+}
+
+func (cm *CompassManagerReconciler) migrationProgressed(ctx context.Context, name types.NamespacedName, message string) (ctrl.Result, error) {
+	cm.backoff.Forget(name)
+	cm.logger(ctx).Infof("Migration of Kyma resource %s: %s", name.Name, message)
+	cm.recordEvent(ctx, name, corev1.EventTypeNormal, EventReasonRuntimeMigrating, "%s", message)
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name, s.ConditionFalse(v1beta2.ConditionTypeMigrated, s.ReasonMigrating, message))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions during migration")
+	}
+	return ctrl.Result{RequeueAfter: cm.requeueTime}, nil
+}
+
+// migrationWaiting requeues the migration which waits for the Compass Runtime Agent, without counting it as a failure or recording an event,
+// as the agent may take several reconciliations to connect. The checks become less frequent with the time passed since the start of the migration,
+// and the migration stalls once it doesn't complete within the migration timeout.
+func (cm *CompassManagerReconciler) migrationWaiting(ctx context.Context, name types.NamespacedName, mapping v1beta2.CompassManagerMapping, message string) (ctrl.Result, error) {
+	startTime, err := time.Parse(time.RFC3339, mapping.Annotations[AnnotationMigrationStartTime])
+	if err != nil {
+		// the migration was started without recording its start time, the timeout is counted from now
+		startTime = time.Now()
+		annotations := map[string]string{AnnotationMigrationStartTime: startTime.UTC().Format(time.RFC3339)}
+		if err := cm.cluster.SetCompassMappingAnnotations(ctx, name, annotations); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to record migration start time for Kyma resource %s", name.Name)
+		}
+	}
+
+	elapsed := time.Since(startTime)
+	if cm.migrationTimeout > 0 && elapsed > cm.migrationTimeout {
+		return cm.migrationTimedOut(ctx, name, fmt.Sprintf("%s, the migration didn't complete within %s", message, cm.migrationTimeout))
+	}
+
+	delay := cm.backoff.DelayAfter(elapsed)
+	cm.logger(ctx).Infof("Migration of Kyma resource %s: %s. Next attempt in %s", name.Name, message, delay)
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name, s.ConditionFalse(v1beta2.ConditionTypeMigrated, s.ReasonMigrating, message))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions during migration")
+	}
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+// migrationTimedOut stalls the migration which didn't complete within the migration timeout. The source Runtime is kept,
+// and the migration is retried once its target changes.
+func (cm *CompassManagerReconciler) migrationTimedOut(ctx context.Context, name types.NamespacedName, message string) (ctrl.Result, error) {
+	cm.logger(ctx).Warnf("Migration of Kyma resource %s timed out: %s", name.Name, message)
+	cm.recordEvent(ctx, name, corev1.EventTypeWarning, EventReasonMigrationTimedOut, "%s", message)
+
+	condErr := cm.cluster.SetCompassMappingConditions(ctx, name,
+		s.ConditionFalse(v1beta2.ConditionTypeMigrated, s.ReasonMigrationTimedOut, message),
+		s.ConditionTrue(v1beta2.ConditionTypeStalled, s.ReasonMigrationTimedOut, message))
+	if condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions after migration timeout")
+	}
+	return ctrl.Result{}, nil
+}
+
+func (cm *CompassManagerReconciler) migrationFailed(ctx context.Context, name types.NamespacedName, err error) (ctrl.Result, error) {
+	cm.logger(ctx).Warnf("Migration of Kyma resource %s failed: %v", name.Name, err)
+	cm.recordError(ctx, name, EventReasonMigrationFailed, "Migration of the Runtime failed", err)
+
+	if condErr := cm.cluster.SetCompassMappingConditions(ctx, name, failureConditions(v1beta2.ConditionTypeMigrated, err)...); condErr != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(condErr, "failed to set Compass Manager Mapping conditions after failed migration")
+	}
+	return cm.retryOrStall(ctx, name, err)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/controllers/mocks"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestMigrationTarget(t *testing.T) {
+	source := runtimeLocation{backend: "eu", globalAccount: "ga"}
+	newMapping := func(annotations map[string]string) v1beta2.CompassManagerMapping {
+		return v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+
+	for name, testCase := range map[string]struct {
+		annotations map[string]string
+		expected    runtimeLocation
+		migrate     bool
+	}{
+		"backend": {
+			annotations: map[string]string{AnnotationMigrationTargetBackend: "us"},
+			expected:    runtimeLocation{backend: "us", globalAccount: "ga"},
+			migrate:     true,
+		},
+		"Global Account": {
+			annotations: map[string]string{AnnotationMigrationTargetGlobalAccount: "ga-new"},
+			expected:    runtimeLocation{backend: "eu", globalAccount: "ga-new"},
+			migrate:     true,
+		},
+		"backend and Global Account": {
+			annotations: map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationTargetGlobalAccount: "ga-new"},
+			expected:    runtimeLocation{backend: "us", globalAccount: "ga-new"},
+			migrate:     true,
+		},
+		"same as source": {
+			annotations: map[string]string{AnnotationMigrationTargetBackend: "eu", AnnotationMigrationTargetGlobalAccount: "ga"},
+			expected:    source,
+			migrate:     false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mapping := newMapping(testCase.annotations)
+			target, migrate := migrationTarget(mapping, source)
+
+			assert.True(t, isMigrationRequested(mapping))
+			assert.Equal(t, testCase.expected, target)
+			assert.Equal(t, testCase.migrate, migrate)
+		})
+	}
+
+	t.Run("migration is not requested without annotations", func(t *testing.T) {
+		assert.False(t, isMigrationRequested(newMapping(map[string]string{AnnotationMigrationRuntimeID: "id"})))
+	})
+}
+
+func TestMigrationRequestChanged(t *testing.T) {
+	newMapping := func(annotations map[string]string) *v1beta2.CompassManagerMapping {
+		return &v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+
+	for name, testCase := range map[string]struct {
+		oldAnnotations map[string]string
+		newAnnotations map[string]string
+		changed        bool
+	}{
+		"migration requested": {
+			newAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us"},
+			changed:        true,
+		},
+		"target changed": {
+			oldAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us"},
+			newAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationTargetGlobalAccount: "ga"},
+			changed:        true,
+		},
+		"migration progressed": {
+			oldAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us"},
+			newAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationRuntimeID: "id"},
+			changed:        false,
+		},
+		"migration completed": {
+			oldAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us"},
+			changed:        false,
+		},
+		"migration canceled": {
+			oldAnnotations: map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationStartedBackend: "us"},
+			newAnnotations: map[string]string{AnnotationMigrationStartedBackend: "us"},
+			changed:        true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.changed, migrationRequestChanged(newMapping(testCase.oldAnnotations), newMapping(testCase.newAnnotations)))
+		})
+	}
+}
+
+func TestDeregisterMigrationRuntime(t *testing.T) {
+	source := runtimeLocation{backend: "eu", globalAccount: "ga"}
+	targetBackend := mock.MatchedBy(func(ctx context.Context) bool {
+		return director.BackendFromContext(ctx) == "us"
+	})
+	newMapping := func(annotations map[string]string) v1beta2.CompassManagerMapping {
+		return v1beta2.CompassManagerMapping{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	newReconciler := func(registrator *mocks.Registrator) *CompassManagerReconciler {
+		return &CompassManagerReconciler{Log: logrus.New(), Registrator: registrator, recorder: record.NewFakeRecorder(10)}
+	}
+
+	t.Run("should deregister the Runtime from the started migration target", func(t *testing.T) {
+		registrator := &mocks.Registrator{}
+		registrator.On("DeregisterFromCompass", targetBackend, "id-target", "ga-started").Return(nil)
+		mapping := newMapping(map[string]string{
+			AnnotationMigrationTargetGlobalAccount:  "ga-requested",
+			AnnotationMigrationStartedBackend:       "us",
+			AnnotationMigrationStartedGlobalAccount: "ga-started",
+			AnnotationMigrationRuntimeID:            "id-target",
+		})
+
+		require.NoError(t, newReconciler(registrator).deregisterMigrationRuntime(context.Background(), mapping, source))
+		registrator.AssertExpectations(t)
+	})
+
+	t.Run("should take the migration target from the requested one and the source", func(t *testing.T) {
+		registrator := &mocks.Registrator{}
+		registrator.On("DeregisterFromCompass", targetBackend, "id-target", "ga").Return(apperrors.NotFound("runtime not found"))
+		mapping := newMapping(map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationRuntimeID: "id-target"})
+
+		require.NoError(t, newReconciler(registrator).deregisterMigrationRuntime(context.Background(), mapping, source))
+		registrator.AssertExpectations(t)
+	})
+
+	t.Run("should return the Director error when the deregistration fails", func(t *testing.T) {
+		registrator := &mocks.Registrator{}
+		registrator.On("DeregisterFromCompass", targetBackend, "id-target", "ga").Return(apperrors.External("unavailable"))
+		mapping := newMapping(map[string]string{AnnotationMigrationTargetBackend: "us", AnnotationMigrationRuntimeID: "id-target"})
+
+		err := newReconciler(registrator).deregisterMigrationRuntime(context.Background(), mapping, source)
+
+		var directorError *DirectorError
+		assert.ErrorAs(t, err, &directorError)
+	})
+
+	t.Run("should skip the mapping without migration in progress", func(t *testing.T) {
+		registrator := &mocks.Registrator{}
+
+		require.NoError(t, newReconciler(registrator).deregisterMigrationRuntime(context.Background(), newMapping(map[string]string{AnnotationMigrationTargetBackend: "us"}), source))
+		registrator.AssertNotCalled(t, "DeregisterFromCompass", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
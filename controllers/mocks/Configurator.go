@@ -2,20 +2,39 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	v1beta2 "github.com/kyma-project/compass-manager/api/v1beta2"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Configurator is an autogenerated mock type for the Configurator type
 type Configurator struct {
 	mock.Mock
 }
 
-// ConfigureCompassRuntimeAgent provides a mock function with given fields: kubeconfig, compassRuntimeID, globalAccount
-func (_m *Configurator) ConfigureCompassRuntimeAgent(kubeconfig []byte, compassRuntimeID string, globalAccount string) error {
-	ret := _m.Called(kubeconfig, compassRuntimeID, globalAccount)
+// ConfigureCompassRuntimeAgent provides a mock function with given fields: ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig
+func (_m *Configurator) ConfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID string, globalAccount string, agentConfig v1beta2.AgentConfiguration) error {
+	ret := _m.Called(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string, string, v1beta2.AgentConfiguration) error); ok {
+		r0 = rf(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeconfigureCompassRuntimeAgent provides a mock function with given fields: ctx, kubeconfig, agentConfig
+func (_m *Configurator) DeconfigureCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, agentConfig v1beta2.AgentConfiguration) error {
+	ret := _m.Called(ctx, kubeconfig, agentConfig)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]byte, string, string) error); ok {
-		r0 = rf(kubeconfig, compassRuntimeID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, v1beta2.AgentConfiguration) error); ok {
+		r0 = rf(ctx, kubeconfig, agentConfig)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -23,6 +42,32 @@ func (_m *Configurator) ConfigureCompassRuntimeAgent(kubeconfig []byte, compassR
 	return r0
 }
 
+// VerifyCompassRuntimeAgent provides a mock function with given fields: ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig
+func (_m *Configurator) VerifyCompassRuntimeAgent(ctx context.Context, kubeconfig []byte, compassRuntimeID string, globalAccount string, agentConfig v1beta2.AgentConfiguration) ([]string, error) {
+	ret := _m.Called(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string, string, v1beta2.AgentConfiguration) ([]string, error)); ok {
+		return rf(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string, string, v1beta2.AgentConfiguration) []string); ok {
+		r0 = rf(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, string, string, v1beta2.AgentConfiguration) error); ok {
+		r1 = rf(ctx, kubeconfig, compassRuntimeID, globalAccount, agentConfig)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewConfigurator creates a new instance of Configurator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewConfigurator(t interface {
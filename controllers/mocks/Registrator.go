@@ -2,20 +2,24 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Registrator is an autogenerated mock type for the Registrator type
 type Registrator struct {
 	mock.Mock
 }
 
-// DeregisterFromCompass provides a mock function with given fields: compassID, globalAccount
-func (_m *Registrator) DeregisterFromCompass(compassID string, globalAccount string) error {
-	ret := _m.Called(compassID, globalAccount)
+// DeregisterFromCompass provides a mock function with given fields: ctx, compassID, globalAccount
+func (_m *Registrator) DeregisterFromCompass(ctx context.Context, compassID string, globalAccount string) error {
+	ret := _m.Called(ctx, compassID, globalAccount)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, compassID, globalAccount)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -23,23 +27,71 @@ func (_m *Registrator) DeregisterFromCompass(compassID string, globalAccount str
 	return r0
 }
 
-// RegisterInCompass provides a mock function with given fields: compassRuntimeLabels
-func (_m *Registrator) RegisterInCompass(compassRuntimeLabels map[string]interface{}) (string, error) {
-	ret := _m.Called(compassRuntimeLabels)
+// FindInCompass provides a mock function with given fields: ctx, compassRuntimeLabels
+func (_m *Registrator) FindInCompass(ctx context.Context, compassRuntimeLabels map[string]interface{}) (string, error) {
+	ret := _m.Called(ctx, compassRuntimeLabels)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]interface{}) (string, error)); ok {
+		return rf(ctx, compassRuntimeLabels)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]interface{}) string); ok {
+		r0 = rf(ctx, compassRuntimeLabels)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, map[string]interface{}) error); ok {
+		r1 = rf(ctx, compassRuntimeLabels)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsRuntimeConnected provides a mock function with given fields: ctx, compassID, globalAccount
+func (_m *Registrator) IsRuntimeConnected(ctx context.Context, compassID string, globalAccount string) (bool, error) {
+	ret := _m.Called(ctx, compassID, globalAccount)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, compassID, globalAccount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, compassID, globalAccount)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, compassID, globalAccount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterInCompass provides a mock function with given fields: ctx, runtimeName, compassRuntimeLabels
+func (_m *Registrator) RegisterInCompass(ctx context.Context, runtimeName string, compassRuntimeLabels map[string]interface{}) (string, error) {
+	ret := _m.Called(ctx, runtimeName, compassRuntimeLabels)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) (string, error)); ok {
-		return rf(compassRuntimeLabels)
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) (string, error)); ok {
+		return rf(ctx, runtimeName, compassRuntimeLabels)
 	}
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) string); ok {
-		r0 = rf(compassRuntimeLabels)
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) string); ok {
+		r0 = rf(ctx, runtimeName, compassRuntimeLabels)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
-		r1 = rf(compassRuntimeLabels)
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]interface{}) error); ok {
+		r1 = rf(ctx, runtimeName, compassRuntimeLabels)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -47,6 +99,20 @@ func (_m *Registrator) RegisterInCompass(compassRuntimeLabels map[string]interfa
 	return r0, r1
 }
 
+// UpdateCompassRuntimeLabels provides a mock function with given fields: ctx, compassID, globalAccount, compassRuntimeLabels
+func (_m *Registrator) UpdateCompassRuntimeLabels(ctx context.Context, compassID string, globalAccount string, compassRuntimeLabels map[string]interface{}) error {
+	ret := _m.Called(ctx, compassID, globalAccount, compassRuntimeLabels)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, compassID, globalAccount, compassRuntimeLabels)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRegistrator creates a new instance of Registrator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRegistrator(t interface {
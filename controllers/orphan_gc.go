@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/controllers/metrics"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	EventReasonOrphanedRuntime             = "OrphanedRuntime"
+	EventReasonOrphanedRuntimeDeregistered = "OrphanedRuntimeDeregistered"
+	EventReasonOrphanedRuntimeDeregFailed  = "OrphanedRuntimeDeregistrationFailed"
+)
+
+// OrphanedRuntimeCollector periodically looks for Runtimes managed by Compass Manager in Compass which have neither
+// a CompassManagerMapping nor a Kyma resource. Orphaned Runtimes are reported, and optionally deregistered
+// once they stay orphaned for the grace period.
+//
+// Global Accounts are taken from Kyma resources and mappings, so orphans in Global Accounts without any Kyma are not found.
+type OrphanedRuntimeCollector struct {
+	Log            *log.Logger
+	Registrator    Registrator
+	directorClient director.Client
+	kubectl        Client
+	recorder       record.EventRecorder
+	metrics        metrics.Metrics
+	routing        DirectorRouting
+	namespace      string
+	period         time.Duration
+	gracePeriod    time.Duration
+	deregister     bool
+
+	mutex     sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+func NewOrphanedRuntimeCollector(
+	mgr manager.Manager,
+	log *log.Logger,
+	r Registrator,
+	directorClient director.Client,
+	namespace string,
+	period time.Duration,
+	gracePeriod time.Duration,
+	deregister bool,
+	metrics metrics.Metrics,
+	routing DirectorRouting,
+) *OrphanedRuntimeCollector {
+	return &OrphanedRuntimeCollector{
+		Log:            log,
+		Registrator:    r,
+		directorClient: directorClient,
+		kubectl:        mgr.GetClient(),
+		recorder:       mgr.GetEventRecorderFor(ManagedBy),
+		metrics:        metrics,
+		routing:        routing,
+		namespace:      namespace,
+		period:         period,
+		gracePeriod:    gracePeriod,
+		deregister:     deregister,
+		firstSeen:      make(map[string]time.Time),
+	}
+}
+
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (c *OrphanedRuntimeCollector) logger(ctx context.Context) *log.Entry {
+	return logging.FromContextOr(ctx, c.Log)
+}
+
+// Start runs the collection loop until the context is cancelled. It implements manager.Runnable.
+func (c *OrphanedRuntimeCollector) Start(ctx context.Context) error {
+	c.logger(ctx).Infof("Starting orphaned Runtime collection every %s, deregistration enabled: %v", c.period, c.deregister)
+	wait.UntilWithContext(ctx, c.collect, c.period)
+	return nil
+}
+
+func (c *OrphanedRuntimeCollector) collect(ctx context.Context) {
+	kymas := &kyma.KymaList{}
+	if err := c.kubectl.List(ctx, kymas, client.InNamespace(c.namespace)); err != nil {
+		c.logger(ctx).Warnf("Failed to list Kyma resources for orphaned Runtime collection: %v", err)
+		return
+	}
+	mappings := &v1beta2.CompassManagerMappingList{}
+	if err := c.kubectl.List(ctx, mappings, client.InNamespace(c.namespace)); err != nil {
+		c.logger(ctx).Warnf("Failed to list Compass Manager Mappings for orphaned Runtime collection: %v", err)
+		return
+	}
+
+	owners := newRuntimeOwners(kymas.Items, mappings.Items, c.routing)
+	orphans := make(map[string]bool)
+	counts := make(map[string]int)
+
+	for _, globalAccount := range owners.sortedGlobalAccounts() {
+		count := 0
+		for _, backend := range owners.sortedBackends(globalAccount) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			backendCtx := director.ContextWithBackend(ctx, backend)
+			runtimes, err := c.directorClient.ListRuntimes(backendCtx, map[string]string{"director_connection_managed_by": ManagedBy}, globalAccount)
+			if err != nil {
+				c.logger(ctx).Warnf("Failed to list Runtimes in Compass %s for Global Account %s: %v", backend, globalAccount, err)
+				continue
+			}
+
+			for _, runtime := range runtimes {
+				if owners.owns(runtime) {
+					continue
+				}
+				count++
+				orphans[runtime.ID] = true
+				c.handleOrphan(backendCtx, runtime, globalAccount)
+			}
+		}
+		counts[globalAccount] = count
+	}
+
+	c.metrics.SetOrphanedRuntimes(counts)
+	c.forgetOwnedRuntimes(orphans)
+}
+
+func (c *OrphanedRuntimeCollector) handleOrphan(ctx context.Context, runtime graphql.RuntimeExt, globalAccount string) {
+	ctx = logging.IntoContext(ctx, c.Log.WithFields(log.Fields{logging.FieldGlobalAccount: globalAccount, logging.FieldCompassRuntimeID: runtime.ID}))
+	now := time.Now()
+
+	c.mutex.Lock()
+	firstSeen, ok := c.firstSeen[runtime.ID]
+	if !ok {
+		firstSeen = now
+		c.firstSeen[runtime.ID] = now
+	}
+	c.mutex.Unlock()
+
+	if !ok {
+		c.logger(ctx).Warnf("Runtime %s (%s) in Global Account %s has neither Compass Manager Mapping nor Kyma resource", runtime.ID, runtime.Name, globalAccount)
+		c.recorder.Eventf(c.eventObject(), corev1.EventTypeWarning, EventReasonOrphanedRuntime,
+			"Runtime %s (%s) in Global Account %s has neither Compass Manager Mapping nor Kyma resource", runtime.ID, runtime.Name, globalAccount)
+	}
+
+	if !c.deregister || now.Sub(firstSeen) < c.gracePeriod {
+		return
+	}
+
+	c.logger(ctx).Infof("Deregistering orphaned Runtime %s in Global Account %s", runtime.ID, globalAccount)
+	if err := c.Registrator.DeregisterFromCompass(ctx, runtime.ID, globalAccount); err != nil {
+		c.logger(ctx).Warnf("Failed to deregister orphaned Runtime %s from Compass: %v", runtime.ID, err)
+		c.recorder.Eventf(c.eventObject(), corev1.EventTypeWarning, EventReasonOrphanedRuntimeDeregFailed,
+			"Failed to deregister orphaned Runtime %s in Global Account %s: %v", runtime.ID, globalAccount, err)
+		return
+	}
+
+	c.recorder.Eventf(c.eventObject(), corev1.EventTypeNormal, EventReasonOrphanedRuntimeDeregistered,
+		"Orphaned Runtime %s (%s) in Global Account %s deregistered", runtime.ID, runtime.Name, globalAccount)
+
+	c.mutex.Lock()
+	delete(c.firstSeen, runtime.ID)
+	c.mutex.Unlock()
+}
+
+// forgetOwnedRuntimes drops Runtimes which are no longer orphaned, so that the grace period starts over if they become orphaned again
+func (c *OrphanedRuntimeCollector) forgetOwnedRuntimes(orphans map[string]bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for runtimeID := range c.firstSeen {
+		if !orphans[runtimeID] {
+			delete(c.firstSeen, runtimeID)
+		}
+	}
+}
+
+// eventObject returns the object orphaned Runtime events are recorded for. Orphaned Runtimes have no resource in the cluster,
+// so the events are recorded for the namespace with Kyma resources.
+func (c *OrphanedRuntimeCollector) eventObject() *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       c.namespace,
+		Namespace:  c.namespace,
+	}
+}
+
+// runtimeOwners holds the Kyma resources and mappings a Runtime in Compass can belong to
+type runtimeOwners struct {
+	compassIDs map[string]bool
+	kymaNames  map[string]bool
+	shootNames map[string]bool
+	// globalAccounts holds the Director backends the Runtimes of every Global Account can be registered in
+	globalAccounts map[string]map[string]bool
+}
+
+func newRuntimeOwners(kymas []kyma.Kyma, mappings []v1beta2.CompassManagerMapping, routing DirectorRouting) runtimeOwners {
+	owners := runtimeOwners{
+		compassIDs:     make(map[string]bool),
+		kymaNames:      make(map[string]bool),
+		shootNames:     make(map[string]bool),
+		globalAccounts: make(map[string]map[string]bool),
+	}
+
+	for _, kymaCR := range kymas {
+		owners.kymaNames[kymaCR.Name] = true
+		if shootName := kymaCR.Labels[LabelShootName]; shootName != "" {
+			owners.shootNames[shootName] = true
+		}
+		if globalAccount := kymaCR.Labels[LabelGlobalAccountID]; globalAccount != "" {
+			owners.addBackend(globalAccount, routing.KymaBackend(kymaCR))
+		}
+	}
+
+	for _, mapping := range mappings {
+		if compassID := mapping.Labels[LabelCompassID]; compassID != "" {
+			owners.compassIDs[compassID] = true
+		}
+		if globalAccount := mapping.Spec.GlobalAccountID; globalAccount != "" {
+			owners.addBackend(globalAccount, routing.MappingBackend(mapping))
+		} else if globalAccount := mapping.Labels[LabelGlobalAccountID]; globalAccount != "" {
+			owners.addBackend(globalAccount, routing.MappingBackend(mapping))
+		}
+	}
+
+	return owners
+}
+
+func (o runtimeOwners) addBackend(globalAccount, backend string) {
+	if o.globalAccounts[globalAccount] == nil {
+		o.globalAccounts[globalAccount] = make(map[string]bool)
+	}
+	o.globalAccounts[globalAccount][backend] = true
+}
+
+// owns returns true if the Runtime is bound to a mapping, or was registered for an existing Kyma resource
+func (o runtimeOwners) owns(runtime graphql.RuntimeExt) bool {
+	if o.compassIDs[runtime.ID] {
+		return true
+	}
+	if kymaName, ok := runtime.Labels[idempotencyKeyLabel].(string); ok && o.kymaNames[kymaName] {
+		return true
+	}
+	shootName, ok := runtime.Labels["gardenerClusterName"].(string)
+	return ok && o.shootNames[shootName]
+}
+
+func (o runtimeOwners) sortedGlobalAccounts() []string {
+	globalAccounts := make([]string, 0, len(o.globalAccounts))
+	for globalAccount := range o.globalAccounts {
+		globalAccounts = append(globalAccounts, globalAccount)
+	}
+	slices.Sort(globalAccounts)
+	return globalAccounts
+}
+
+func (o runtimeOwners) sortedBackends(globalAccount string) []string {
+	backends := make([]string, 0, len(o.globalAccounts[globalAccount]))
+	for backend := range o.globalAccounts[globalAccount] {
+		backends = append(backends, backend)
+	}
+	slices.Sort(backends)
+	return backends
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	cmmocks "github.com/kyma-project/compass-manager/controllers/mocks"
+	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRuntimeOwners(t *testing.T) {
+	owners := newRuntimeOwners(
+		[]kyma.Kyma{{ObjectMeta: metav1.ObjectMeta{
+			Name:   "kyma",
+			Labels: map[string]string{LabelShootName: "shoot", LabelGlobalAccountID: "ga-kyma"},
+		}}},
+		[]v1beta2.CompassManagerMapping{{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelCompassID: "mapped", LabelGlobalAccountID: "ga-label", LabelDirectorBackend: "us"}},
+		}, {
+			Spec: v1beta2.CompassManagerMappingSpec{GlobalAccountID: "ga-spec"},
+		}, {
+			Spec: v1beta2.CompassManagerMappingSpec{GlobalAccountID: "ga-kyma"},
+		}},
+		DirectorRouting{DefaultBackend: "eu", GlobalAccounts: map[string]string{"ga-kyma": "asia"}},
+	)
+
+	assert.Equal(t, []string{"ga-kyma", "ga-label", "ga-spec"}, owners.sortedGlobalAccounts())
+	assert.Equal(t, []string{"asia", "eu"}, owners.sortedBackends("ga-kyma"))
+	assert.Equal(t, []string{"us"}, owners.sortedBackends("ga-label"))
+	assert.Equal(t, []string{"eu"}, owners.sortedBackends("ga-spec"))
+
+	for name, testCase := range map[string]struct {
+		runtime graphql.RuntimeExt
+		owned   bool
+	}{
+		"bound to mapping": {
+			runtime: graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "mapped"}},
+			owned:   true,
+		},
+		"registered for Kyma resource": {
+			runtime: graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "other"}, Labels: graphql.Labels{idempotencyKeyLabel: "kyma"}},
+			owned:   true,
+		},
+		"registered for shoot": {
+			runtime: graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "other"}, Labels: graphql.Labels{"gardenerClusterName": "shoot"}},
+			owned:   true,
+		},
+		"orphaned": {
+			runtime: graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "other"}, Labels: graphql.Labels{idempotencyKeyLabel: "deleted", "gardenerClusterName": "deleted"}},
+			owned:   false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.owned, owners.owns(testCase.runtime))
+		})
+	}
+}
+
+func TestHandleOrphan(t *testing.T) {
+	orphan := graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "orphan", Name: "orphan"}}
+
+	newCollector := func(registrator Registrator, gracePeriod time.Duration, deregister bool) *OrphanedRuntimeCollector {
+		return &OrphanedRuntimeCollector{
+			Log:         logrus.New(),
+			Registrator: registrator,
+			recorder:    record.NewFakeRecorder(10),
+			namespace:   "kcp-system",
+			gracePeriod: gracePeriod,
+			deregister:  deregister,
+			firstSeen:   make(map[string]time.Time),
+		}
+	}
+
+	t.Run("should only report orphan when deregistration is disabled", func(t *testing.T) {
+		registrator := &cmmocks.Registrator{}
+		collector := newCollector(registrator, 0, false)
+
+		collector.handleOrphan(context.Background(), orphan, "ga")
+
+		registrator.AssertNotCalled(t, "DeregisterFromCompass")
+		assert.Len(t, collector.firstSeen, 1)
+	})
+
+	t.Run("should not deregister orphan before grace period passes", func(t *testing.T) {
+		registrator := &cmmocks.Registrator{}
+		collector := newCollector(registrator, time.Hour, true)
+
+		collector.handleOrphan(context.Background(), orphan, "ga")
+
+		registrator.AssertNotCalled(t, "DeregisterFromCompass")
+	})
+
+	t.Run("should deregister orphan after grace period", func(t *testing.T) {
+		registrator := &cmmocks.Registrator{}
+		registrator.On("DeregisterFromCompass", mock.Anything, "orphan", "ga").Return(nil)
+		collector := newCollector(registrator, time.Hour, true)
+		collector.firstSeen["orphan"] = time.Now().Add(-2 * time.Hour)
+
+		collector.handleOrphan(context.Background(), orphan, "ga")
+
+		registrator.AssertExpectations(t)
+		assert.Empty(t, collector.firstSeen)
+	})
+
+	t.Run("should forget Runtimes which are no longer orphaned", func(t *testing.T) {
+		collector := newCollector(&cmmocks.Registrator{}, time.Hour, true)
+		collector.firstSeen["orphan"] = time.Now()
+		collector.firstSeen["owned"] = time.Now()
+
+		collector.forgetOwnedRuntimes(map[string]bool{"orphan": true})
+
+		assert.Contains(t, collector.firstSeen, "orphan")
+		assert.NotContains(t, collector.firstSeen, "owned")
+	})
+}
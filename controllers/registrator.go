@@ -1,22 +1,24 @@
 package controllers
 
 import (
+	"context"
+	"crypto/sha256"
 	"math/rand"
-	"time"
+	"slices"
 
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
 	"github.com/kyma-project/compass-manager/internal/apperrors"
 	"github.com/kyma-project/compass-manager/internal/director"
-	"github.com/kyma-project/compass-manager/internal/util"
+	"github.com/kyma-project/compass-manager/internal/logging"
 	"github.com/kyma-project/compass-manager/pkg/gqlschema"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	nameIDLen         = 4
-	retryTime         = 5
-	extendedRetryTime = 10
-	attempts          = 3
+	// idempotencyKeyLabel is the label of the Runtime in Compass identifying the Kyma runtime it was registered for
+	idempotencyKeyLabel = "compass_manager_idempotency_key"
+
+	nameIDLen = 4
 )
 
 type CompassRegistrator struct {
@@ -31,43 +33,134 @@ func NewCompassRegistrator(directorClient director.Client, log *logrus.Logger) *
 	}
 }
 
-func (r *CompassRegistrator) RegisterInCompass(compassRuntimeLabels map[string]interface{}) (string, error) {
-	var runtimeID string
-	runtimeInput, err := createRuntimeInput(compassRuntimeLabels)
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (r *CompassRegistrator) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContextOr(ctx, r.Log)
+}
+
+// RegisterInCompass registers the Runtime in Compass. The Runtime labelled with the idempotency key by a previous attempt
+// is returned instead of registering a new one, e.g. when the process was stopped before the Runtime ID was stored in the mapping.
+func (r *CompassRegistrator) RegisterInCompass(ctx context.Context, runtimeName string, compassRuntimeLabels map[string]interface{}) (string, error) {
+	runtimeInput, err := createRuntimeInput(runtimeName, compassRuntimeLabels)
 	if err != nil {
 		return "", err
 	}
+	globalAccount := compassRuntimeLabels["global_account_id"].(string)
+	idempotencyKey, _ := compassRuntimeLabels[idempotencyKeyLabel].(string)
+
+	runtimeID, appErr := r.findRegisteredRuntime(ctx, idempotencyKey, globalAccount)
+	if appErr != nil {
+		return "", appErr
+	}
+	if runtimeID != "" {
+		return runtimeID, nil
+	}
 
-	err = util.RetryOnError(retryTime*time.Second, attempts, "Error while registering runtime in Director: %s", func() (err apperrors.AppError) {
-		runtimeID, err = r.Client.CreateRuntime(runtimeInput, compassRuntimeLabels["global_account_id"].(string))
-		return
-	})
+	runtimeID, appErr = r.Client.CreateRuntime(ctx, runtimeInput, globalAccount)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	return runtimeID, nil
+}
+
+// findRegisteredRuntime returns the ID of the Runtime registered with the given idempotency key, or an empty ID if there is none
+func (r *CompassRegistrator) findRegisteredRuntime(ctx context.Context, idempotencyKey, globalAccount string) (string, apperrors.AppError) {
+	if idempotencyKey == "" {
+		return "", nil
+	}
 
+	runtimes, err := r.Client.ListRuntimes(ctx, map[string]string{idempotencyKeyLabel: idempotencyKey}, globalAccount)
 	if err != nil {
 		return "", err
 	}
 
-	return runtimeID, nil
+	switch len(runtimes) {
+	case 0:
+		return "", nil
+	case 1:
+		r.logger(ctx).Infof("Runtime %s with idempotency key %s is already registered in Compass", runtimes[0].ID, idempotencyKey)
+		return runtimes[0].ID, nil
+	default:
+		return "", apperrors.Internalf("Found %d Runtimes in Director with idempotency key %s", len(runtimes), idempotencyKey).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeNotUnique)
+	}
+}
+
+// FindInCompass looks up the Runtime registered for the Kyma runtime by the broker instance ID and the shoot name
+func (r *CompassRegistrator) FindInCompass(ctx context.Context, compassRuntimeLabels map[string]interface{}) (string, error) {
+	filters := make(map[string]string)
+	for _, key := range []string{"broker_instance_id", "gardenerClusterName"} {
+		value, _ := compassRuntimeLabels[key].(string)
+		if value == "" {
+			return "", nil
+		}
+		filters[key] = value
+	}
+	globalAccount, _ := compassRuntimeLabels["global_account_id"].(string)
+
+	runtimes, err := r.Client.ListRuntimes(ctx, filters, globalAccount)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(runtimes) {
+	case 0:
+		return "", nil
+	case 1:
+		return runtimes[0].ID, nil
+	default:
+		return "", apperrors.Internalf("Found %d Runtimes in Director with labels %v", len(runtimes), filters).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeNotUnique)
+	}
 }
 
-func (r *CompassRegistrator) DeregisterFromCompass(compassID, globalAccount string) error {
-	err := util.RetryOnError(extendedRetryTime*time.Second, attempts, "Error while unregistering runtime in Director: %s", func() (err apperrors.AppError) {
-		err = r.Client.DeleteRuntime(compassID, globalAccount)
-		return
-	})
+func (r *CompassRegistrator) DeregisterFromCompass(ctx context.Context, compassID, globalAccount string) error {
+	err := r.Client.DeleteRuntime(ctx, compassID, globalAccount)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *CompassRegistrator) RefreshCompassToken(compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, error) {
-	var token graphql.OneTimeTokenForRuntimeExt
-	err := util.RetryOnError(retryTime*time.Second, attempts, "Error while refreshing OneTime token in Director: %s", func() (err apperrors.AppError) {
-		token, err = r.Client.GetConnectionToken(compassID, globalAccount)
-		return
-	})
+func (r *CompassRegistrator) UpdateCompassRuntimeLabels(ctx context.Context, compassID, globalAccount string, compassRuntimeLabels map[string]interface{}) error {
+	runtime, err := r.Client.GetRuntime(ctx, compassID, globalAccount)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(compassRuntimeLabels))
+	for key := range compassRuntimeLabels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value, ok := compassRuntimeLabels[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if current, ok := runtime.Labels[key]; ok && current == value {
+			continue
+		}
+
+		r.logger(ctx).Infof("Updating label %s of Runtime %s in Compass", key, compassID)
+		if err := r.Client.SetRuntimeLabel(ctx, compassID, globalAccount, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *CompassRegistrator) IsRuntimeConnected(ctx context.Context, compassID, globalAccount string) (bool, error) {
+	runtime, err := r.Client.GetRuntime(ctx, compassID, globalAccount)
+	if err != nil {
+		return false, err
+	}
 
+	return runtime.Status != nil && runtime.Status.Condition == graphql.RuntimeStatusConditionConnected, nil
+}
+
+func (r *CompassRegistrator) RefreshCompassToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, error) {
+	token, err := r.Client.GetConnectionToken(ctx, compassID, globalAccount)
 	if err != nil {
 		return graphql.OneTimeTokenForRuntimeExt{}, err
 	}
@@ -75,9 +168,12 @@ func (r *CompassRegistrator) RefreshCompassToken(compassID, globalAccount string
 	return token, nil
 }
 
-func createRuntimeInput(compassRuntimeLabels map[string]interface{}) (*gqlschema.RuntimeInput, error) {
+func createRuntimeInput(runtimeName string, compassRuntimeLabels map[string]interface{}) (*gqlschema.RuntimeInput, error) {
 	runtimeInput := &gqlschema.RuntimeInput{}
-	runtimeInput.Name = compassRuntimeLabels["gardenerClusterName"].(string) + "-" + generateRandomText(nameIDLen)
+	runtimeInput.Name = runtimeName
+	if runtimeInput.Name == "" {
+		runtimeInput.Name = compassRuntimeLabels["gardenerClusterName"].(string) + "-" + runtimeNameSuffix(compassRuntimeLabels)
+	}
 
 	err := runtimeInput.Labels.UnmarshalGQL(compassRuntimeLabels)
 	if err != nil {
@@ -87,6 +183,22 @@ func createRuntimeInput(compassRuntimeLabels map[string]interface{}) (*gqlschema
 	return runtimeInput, nil
 }
 
+// runtimeNameSuffix returns the suffix of the generated Runtime name. It's derived from the idempotency key, so that every attempt to register the Runtime uses the same name.
+func runtimeNameSuffix(compassRuntimeLabels map[string]interface{}) string {
+	idempotencyKey, _ := compassRuntimeLabels[idempotencyKeyLabel].(string)
+	if idempotencyKey == "" {
+		return generateRandomText(nameIDLen)
+	}
+
+	letterRunes := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	hash := sha256.Sum256([]byte(idempotencyKey))
+	runes := make([]rune, nameIDLen)
+	for i := range runes {
+		runes[i] = letterRunes[int(hash[i])%len(letterRunes)]
+	}
+	return string(runes)
+}
+
 func generateRandomText(count int) string {
 	letterRunes := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 	runes := make([]rune, count)
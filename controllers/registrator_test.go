@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateCompassRuntimeLabels(t *testing.T) {
+	t.Run("should set only labels that changed", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("GetRuntime", mock.Anything, "compassID", "globalAccount").Return(graphql.RuntimeExt{
+			Labels: graphql.Labels{
+				"broker_plan_name":     "azure",
+				"global_subaccount_id": "subaccount",
+				"scenarios":            []interface{}{"DEFAULT"},
+			},
+		}, nil)
+		mockDirectorClient.On("SetRuntimeLabel", mock.Anything, "compassID", "globalAccount", "broker_plan_name", "aws").Return(nil)
+		mockDirectorClient.On("SetRuntimeLabel", mock.Anything, "compassID", "globalAccount", "gardenerClusterName", "shoot").Return(nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		err := registrator.UpdateCompassRuntimeLabels(context.Background(), "compassID", "globalAccount", map[string]interface{}{
+			"broker_plan_name":     "aws",
+			"global_subaccount_id": "subaccount",
+			"gardenerClusterName":  "shoot",
+			"broker_instance_id":   "",
+		})
+
+		require.NoError(t, err)
+		mockDirectorClient.AssertExpectations(t)
+		mockDirectorClient.AssertNumberOfCalls(t, "SetRuntimeLabel", 2)
+	})
+
+	t.Run("should return not found error when Runtime doesn't exist", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("GetRuntime", mock.Anything, "compassID", "globalAccount").
+			Return(graphql.RuntimeExt{}, apperrors.NotFound("runtime not found").SetComponent(apperrors.ErrCompassDirector))
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		err := registrator.UpdateCompassRuntimeLabels(context.Background(), "compassID", "globalAccount", map[string]interface{}{"broker_plan_name": "aws"})
+
+		require.Error(t, err)
+		assert.True(t, isRuntimeNotFound(err))
+		mockDirectorClient.AssertNotCalled(t, "SetRuntimeLabel")
+	})
+}
+
+func TestFindInCompass(t *testing.T) {
+	compassRuntimeLabels := map[string]interface{}{
+		"broker_instance_id":  "instance",
+		"gardenerClusterName": "shoot",
+		"global_account_id":   "globalAccount",
+	}
+	filters := map[string]string{
+		"broker_instance_id":  "instance",
+		"gardenerClusterName": "shoot",
+	}
+
+	t.Run("should return ID of the registered Runtime", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("ListRuntimes", mock.Anything, filters, "globalAccount").Return([]graphql.RuntimeExt{{Runtime: graphql.Runtime{ID: "compassID"}}}, nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.FindInCompass(context.Background(), compassRuntimeLabels)
+
+		require.NoError(t, err)
+		assert.Equal(t, "compassID", compassID)
+	})
+
+	t.Run("should return empty ID when Runtime is not registered", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("ListRuntimes", mock.Anything, filters, "globalAccount").Return(nil, nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.FindInCompass(context.Background(), compassRuntimeLabels)
+
+		require.NoError(t, err)
+		assert.Empty(t, compassID)
+	})
+
+	t.Run("should return error when more than one Runtime matches", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("ListRuntimes", mock.Anything, filters, "globalAccount").Return([]graphql.RuntimeExt{
+			{Runtime: graphql.Runtime{ID: "compassID"}},
+			{Runtime: graphql.Runtime{ID: "otherID"}},
+		}, nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.FindInCompass(context.Background(), compassRuntimeLabels)
+
+		require.Error(t, err)
+		assert.Empty(t, compassID)
+	})
+
+	t.Run("should not query Director when labels identifying the Runtime are missing", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.FindInCompass(context.Background(), map[string]interface{}{"gardenerClusterName": "shoot", "broker_instance_id": ""})
+
+		require.NoError(t, err)
+		assert.Empty(t, compassID)
+		mockDirectorClient.AssertNotCalled(t, "ListRuntimes")
+	})
+}
+
+func TestRegisterInCompass(t *testing.T) {
+	compassRuntimeLabels := map[string]interface{}{
+		"gardenerClusterName":             "shoot",
+		"global_account_id":               "globalAccount",
+		"compass_manager_idempotency_key": "kyma-name",
+	}
+	filters := map[string]string{"compass_manager_idempotency_key": "kyma-name"}
+
+	t.Run("should return Runtime registered by previous attempt", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("ListRuntimes", mock.Anything, filters, "globalAccount").Return([]graphql.RuntimeExt{{Runtime: graphql.Runtime{ID: "compassID"}}}, nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.RegisterInCompass(context.Background(), "", compassRuntimeLabels)
+
+		require.NoError(t, err)
+		assert.Equal(t, "compassID", compassID)
+		mockDirectorClient.AssertNotCalled(t, "CreateRuntime")
+	})
+
+	t.Run("should register Runtime when it's not registered yet", func(t *testing.T) {
+		mockDirectorClient := &mocks.Client{}
+		mockDirectorClient.On("ListRuntimes", mock.Anything, filters, "globalAccount").Return(nil, nil)
+		mockDirectorClient.On("CreateRuntime", mock.Anything, mock.AnythingOfType("*gqlschema.RuntimeInput"), "globalAccount").Return("compassID", nil)
+
+		registrator := NewCompassRegistrator(mockDirectorClient, logrus.New())
+
+		compassID, err := registrator.RegisterInCompass(context.Background(), "", compassRuntimeLabels)
+
+		require.NoError(t, err)
+		assert.Equal(t, "compassID", compassID)
+		mockDirectorClient.AssertExpectations(t)
+	})
+
+	t.Run("should generate the same Runtime name for the same idempotency key", func(t *testing.T) {
+		first, err := createRuntimeInput("", compassRuntimeLabels)
+		require.NoError(t, err)
+		second, err := createRuntimeInput("", compassRuntimeLabels)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.Name, second.Name)
+		assert.Regexp(t, "^shoot-[a-zA-Z]{4}$", first.Name)
+	})
+}
+
+func TestIsRuntimeConnected(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		status    *graphql.RuntimeStatus
+		connected bool
+	}{
+		"connected":      {status: &graphql.RuntimeStatus{Condition: graphql.RuntimeStatusConditionConnected}, connected: true},
+		"initial":        {status: &graphql.RuntimeStatus{Condition: graphql.RuntimeStatusConditionInitial}, connected: false},
+		"without status": {status: nil, connected: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockDirectorClient := &mocks.Client{}
+			mockDirectorClient.On("GetRuntime", mock.Anything, "compassID", "globalAccount").
+				Return(graphql.RuntimeExt{Runtime: graphql.Runtime{Status: testCase.status}}, nil)
+
+			connected, err := NewCompassRegistrator(mockDirectorClient, logrus.New()).IsRuntimeConnected(context.Background(), "compassID", "globalAccount")
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.connected, connected)
+		})
+	}
+}
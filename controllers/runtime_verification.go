@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	s "github.com/kyma-project/compass-manager/controllers/status"
+	"github.com/kyma-project/compass-manager/internal/director"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// verificationConcurrency is the number of Runtimes verified in Compass in parallel
+	verificationConcurrency = 10
+	// verificationMappingTimeout limits the verification of a single Runtime, so that slow Director responses don't block the verification of the others
+	verificationMappingTimeout = 2 * time.Minute
+)
+
+// CompassRuntimeVerifier periodically checks that Runtimes with Ready mappings still exist in Compass, and updates their labels
+// when labels of the Kyma resource change. Runtimes deleted from Compass are unbound from the mapping, and the Kyma resource is
+// reconciled to register the Runtime again.
+type CompassRuntimeVerifier struct {
+	Log                 *log.Logger
+	Registrator         Registrator
+	cluster             *ControlPlaneInterface
+	events              chan event.GenericEvent
+	verificationPeriod  time.Duration
+	enabledRegistration bool
+}
+
+func NewCompassRuntimeVerifier(
+	mgr manager.Manager,
+	log *log.Logger,
+	r Registrator,
+	verificationPeriod time.Duration,
+	enabledRegistration bool,
+	dryRun bool,
+) *CompassRuntimeVerifier {
+	return &CompassRuntimeVerifier{
+		Log:                 log,
+		Registrator:         r,
+		cluster:             NewControlPlaneInterface(mgr.GetClient(), log, dryRun),
+		events:              make(chan event.GenericEvent),
+		verificationPeriod:  verificationPeriod,
+		enabledRegistration: enabledRegistration,
+	}
+}
+
+// logger returns the logger with the fields of the Kyma resource processed with ctx
+func (v *CompassRuntimeVerifier) logger(ctx context.Context) *log.Entry {
+	return logging.FromContextOr(ctx, v.Log)
+}
+
+// Source returns the source of reconciliation requests for Kyma resources whose Runtime must be registered again
+func (v *CompassRuntimeVerifier) Source() source.Source {
+	return source.Channel(v.events, &handler.EnqueueRequestForObject{})
+}
+
+// Start runs the verification loop until the context is cancelled. It implements manager.Runnable.
+func (v *CompassRuntimeVerifier) Start(ctx context.Context) error {
+	v.logger(ctx).Infof("Starting Compass Runtime verification every %s", v.verificationPeriod)
+	wait.UntilWithContext(ctx, v.verify, v.verificationPeriod)
+	return nil
+}
+
+func (v *CompassRuntimeVerifier) verify(ctx context.Context) {
+	mappings := &v1beta2.CompassManagerMappingList{}
+	if err := v.cluster.kubectl.List(ctx, mappings); err != nil {
+		v.logger(ctx).Warnf("Failed to list Compass Manager Mappings for Compass Runtime verification: %v", err)
+		return
+	}
+
+	group := errgroup.Group{}
+	group.SetLimit(verificationConcurrency)
+	for i := range mappings.Items {
+		if ctx.Err() != nil {
+			break
+		}
+		group.Go(func() error {
+			v.verifyMapping(ctx, mappings.Items[i])
+			return nil
+		})
+	}
+	_ = group.Wait()
+}
+
+func (v *CompassRuntimeVerifier) verifyMapping(ctx context.Context, mapping v1beta2.CompassManagerMapping) {
+	if !isReadyForResync(mapping) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verificationMappingTimeout)
+	defer cancel()
+
+	compassRuntimeID := mapping.Labels[LabelCompassID]
+	kymaName := mappingKymaName(mapping)
+	// mappings without the recorded backend belong to the default one
+	ctx = director.ContextWithBackend(ctx, mapping.Labels[LabelDirectorBackend])
+	ctx = logging.IntoContext(ctx, v.Log.WithFields(log.Fields{logging.FieldKyma: kymaName.Name, logging.FieldCompassRuntimeID: compassRuntimeID}))
+
+	kymaCR, err := v.cluster.GetKyma(ctx, kymaName)
+	if err != nil {
+		v.logger(ctx).Warnf("Failed to obtain Kyma resource %s for Compass Runtime verification: %v", kymaName.Name, err)
+		return
+	}
+	ctx = logging.WithFields(ctx, log.Fields{logging.FieldGlobalAccount: mappingGlobalAccount(mapping, kymaCR)})
+
+	err = v.Registrator.UpdateCompassRuntimeLabels(ctx, compassRuntimeID, mappingGlobalAccount(mapping, kymaCR), mappingCompassRuntimeLabels(kymaCR.Labels, mapping.Spec))
+	if err == nil {
+		return
+	}
+
+	if directorCondition, ok := s.DirectorCondition(err); ok {
+		if condErr := v.cluster.SetCompassMappingConditions(ctx, kymaName, directorCondition); condErr != nil {
+			v.logger(ctx).Warnf("Failed to set %s condition for Kyma resource %s: %v", v1beta2.ConditionTypeDirectorReachable, kymaName.Name, condErr)
+		}
+	}
+
+	if !isRuntimeNotFound(err) {
+		v.logger(ctx).Warnf("Failed to verify Runtime %s in Compass for Kyma resource %s: %v", compassRuntimeID, kymaName.Name, err)
+		return
+	}
+
+	v.logger(ctx).Warnf("Runtime %s for Kyma resource %s doesn't exist in Compass", compassRuntimeID, kymaName.Name)
+	if !v.enabledRegistration {
+		if condErr := v.cluster.SetCompassMappingConditions(ctx, kymaName, s.ConditionFromError(v1beta2.ConditionTypeRuntimeRegistered, err)); condErr != nil {
+			v.logger(ctx).Warnf("Failed to set %s condition for Kyma resource %s: %v", v1beta2.ConditionTypeRuntimeRegistered, kymaName.Name, condErr)
+		}
+		return
+	}
+
+	if err := v.cluster.UpsertCompassMapping(ctx, kymaName, ""); err != nil {
+		v.logger(ctx).Warnf("Failed to remove Runtime ID from Compass Manager Mapping for Kyma resource %s: %v", kymaName.Name, err)
+		return
+	}
+	if err := v.cluster.SetCompassMappingStatus(ctx, kymaName, s.Processing, s.ConditionFromError(v1beta2.ConditionTypeRuntimeRegistered, err)); err != nil {
+		v.logger(ctx).Warnf("Failed to update Compass Manager Mapping status for Kyma resource %s: %v", kymaName.Name, err)
+		return
+	}
+
+	v.logger(ctx).Infof("Runtime for Kyma resource %s will be registered in Compass again", kymaName.Name)
+	select {
+	case v.events <- event.GenericEvent{Object: &kymaCR}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,110 @@
+package status
+
+import (
+	"regexp"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonRegistered        = "Registered"
+	ReasonAdopted           = "Adopted"
+	ReasonConfigured        = "Configured"
+	ReasonKubeconfigFound   = "KubeconfigFound"
+	ReasonDirectorResponded = "DirectorResponded"
+	ReasonPermanentError    = "PermanentError"
+	ReasonRetriesExhausted  = "RetriesExhausted"
+	ReasonRetrying          = "Retrying"
+	ReasonMigrating         = "Migrating"
+	ReasonMigrated          = "Migrated"
+	ReasonMigrationCanceled = "MigrationCanceled"
+	ReasonMigrationTimedOut = "MigrationTimedOut"
+)
+
+var invalidReasonChars = regexp.MustCompile(`[^A-Za-z0-9_,:]`) //nolint:gochecknoglobals
+
+// ConditionTrue returns a condition with status True
+func ConditionTrue(conditionType, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// ConditionFalse returns a condition with status False
+func ConditionFalse(conditionType, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// ConditionFromError returns a condition with status False, the reason is taken from the apperrors.AppError wrapped in err
+func ConditionFromError(conditionType string, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  Reason(err),
+		Message: logging.Redact(err.Error()),
+	}
+}
+
+// DirectorCondition returns the DirectorReachable condition for an error returned by an operation calling the Director.
+// It returns false if the error did not come from the Director or the OAuth server.
+func DirectorCondition(err error) (metav1.Condition, bool) {
+	var appErr apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return metav1.Condition{}, false
+	}
+
+	switch {
+	case appErr.Component() == apperrors.ErrMpsOAuth2,
+		appErr.Reason() == apperrors.ErrDirectorRequestFailed,
+		appErr.Component() == apperrors.ErrCompassDirector && appErr.Code() == apperrors.CodeBadGateway:
+		return ConditionFromError(v1beta2.ConditionTypeDirectorReachable, err), true
+	case appErr.Component() == apperrors.ErrCompassDirector:
+		// Director responded with an error related to the request, it is reachable
+		return ConditionTrue(v1beta2.ConditionTypeDirectorReachable, ReasonDirectorResponded, ""), true
+	default:
+		return metav1.Condition{}, false
+	}
+}
+
+// Reason returns the reason of the apperrors.AppError wrapped in err, formatted to be used in a condition
+func Reason(err error) string {
+	reason := apperrors.ErrCompassManagerInternal
+
+	var appErr apperrors.AppError
+	if errors.As(err, &appErr) && appErr.Reason() != "" {
+		reason = appErr.Reason()
+	}
+
+	return invalidReasonChars.ReplaceAllString(string(reason), "_")
+}
+
+// SetConditions updates the conditions in status. The message of the last False condition with a message is stored as LastError.
+func SetConditions(status *v1beta2.CompassManagerMappingStatus, generation int64, conditions ...metav1.Condition) {
+	for _, condition := range conditions {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+
+		if condition.Status == metav1.ConditionFalse && condition.Message != "" {
+			status.LastError = condition.Message
+		}
+	}
+}
+
+// IsStalled returns true if the Stalled condition was set for the given generation of the mapping
+func IsStalled(status v1beta2.CompassManagerMappingStatus, generation int64) bool {
+	stalled := meta.FindStatusCondition(status.Conditions, v1beta2.ConditionTypeStalled)
+	return stalled != nil && stalled.Status == metav1.ConditionTrue && stalled.ObservedGeneration == generation
+}
@@ -0,0 +1,175 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/compass-manager/api/v1beta2"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_reason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "Should return reason of the AppError",
+			err:  apperrors.Internal("nil response").SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorNilResponse),
+			want: "err_director_nil_response",
+		},
+		{
+			name: "Should return reason of the wrapped AppError",
+			err:  errors.Wrap(apperrors.BadGateway("unauthorized").SetComponent(apperrors.ErrCompassDirector).SetReason("Unauthorized"), "failed to register"),
+			want: "Unauthorized",
+		},
+		{
+			name: "Should return internal reason for errors other than AppError",
+			err:  errors.New("some error"),
+			want: "err_compass_manager_internal",
+		},
+		{
+			name: "Should return internal reason for AppError without reason",
+			err:  apperrors.External("bad status").SetComponent(apperrors.ErrMpsOAuth2),
+			want: "err_compass_manager_internal",
+		},
+		{
+			name: "Should replace characters not allowed in condition reason",
+			err:  apperrors.Internal("unknown").SetComponent(apperrors.ErrCompassDirector).SetReason("ErrorType(123)"),
+			want: "ErrorType_123_",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Reason(tt.err))
+		})
+	}
+}
+
+func Test_conditionFromError(t *testing.T) {
+	err := apperrors.External(`Get token call returned unexpected status: 500. Response body: {"access_token": "access-token"}`).SetComponent(apperrors.ErrMpsOAuth2)
+
+	condition := ConditionFromError(v1beta2.ConditionTypeDirectorReachable, err)
+
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "unexpected status: 500")
+	assert.NotContains(t, condition.Message, "access-token")
+}
+
+func Test_directorCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantOK     bool
+		wantStatus metav1.ConditionStatus
+	}{
+		{
+			name:       "Should report Director as unreachable on authorization failure",
+			err:        apperrors.BadGateway("unauthorized").SetComponent(apperrors.ErrCompassDirector).SetReason("Unauthorized"),
+			wantOK:     true,
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name:       "Should report Director as unreachable on failed request",
+			err:        apperrors.Internal("connection refused").SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRequestFailed),
+			wantOK:     true,
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name:       "Should report Director as unreachable when OAuth token can't be fetched",
+			err:        apperrors.External("bad status").SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed),
+			wantOK:     true,
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name:       "Should report Director as reachable when it rejected the request",
+			err:        apperrors.BadRequest("not unique").SetComponent(apperrors.ErrCompassDirector).SetReason("NotUnique"),
+			wantOK:     true,
+			wantStatus: metav1.ConditionTrue,
+		},
+		{
+			name:   "Should not report Director condition for Runtime errors",
+			err:    apperrors.Internal("forbidden").SetComponent(apperrors.ErrKymaRuntime).SetReason(apperrors.ErrRuntimeAPIRequestFailed),
+			wantOK: false,
+		},
+		{
+			name:   "Should not report Director condition for errors other than AppError",
+			err:    errors.New("some error"),
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, ok := DirectorCondition(tt.err)
+			require.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, v1beta2.ConditionTypeDirectorReachable, condition.Type)
+				assert.Equal(t, tt.wantStatus, condition.Status)
+			}
+		})
+	}
+}
+
+func Test_setConditions(t *testing.T) {
+	t.Run("Should set conditions with observed generation and last error", func(t *testing.T) {
+		// given
+		status := v1beta2.CompassManagerMappingStatus{}
+		err := apperrors.BadGateway("unauthorized").SetComponent(apperrors.ErrCompassDirector).SetReason("Unauthorized")
+
+		// when
+		SetConditions(&status, 3,
+			ConditionTrue(v1beta2.ConditionTypeKubeconfigAvailable, ReasonKubeconfigFound, ""),
+			ConditionFromError(v1beta2.ConditionTypeRuntimeRegistered, err))
+
+		// then
+		require.Len(t, status.Conditions, 2)
+		assert.True(t, meta.IsStatusConditionTrue(status.Conditions, v1beta2.ConditionTypeKubeconfigAvailable))
+		registered := meta.FindStatusCondition(status.Conditions, v1beta2.ConditionTypeRuntimeRegistered)
+		require.NotNil(t, registered)
+		assert.Equal(t, metav1.ConditionFalse, registered.Status)
+		assert.Equal(t, "Unauthorized", registered.Reason)
+		assert.Equal(t, int64(3), registered.ObservedGeneration)
+		assert.False(t, registered.LastTransitionTime.IsZero())
+		assert.Equal(t, "unauthorized", status.LastError)
+	})
+
+	t.Run("Should keep transition time when condition status doesn't change", func(t *testing.T) {
+		// given
+		status := v1beta2.CompassManagerMappingStatus{}
+		SetConditions(&status, 1, ConditionTrue(v1beta2.ConditionTypeAgentConfigured, ReasonConfigured, ""))
+		transitionTime := metav1.NewTime(status.Conditions[0].LastTransitionTime.Add(-time.Hour))
+		status.Conditions[0].LastTransitionTime = transitionTime
+
+		// when
+		SetConditions(&status, 2, ConditionTrue(v1beta2.ConditionTypeAgentConfigured, ReasonConfigured, ""))
+
+		// then
+		assert.Equal(t, transitionTime, status.Conditions[0].LastTransitionTime)
+		assert.Equal(t, int64(2), status.Conditions[0].ObservedGeneration)
+	})
+}
+
+func Test_isStalled(t *testing.T) {
+	// given
+	status := v1beta2.CompassManagerMappingStatus{LastError: "tenant not found"}
+
+	// when
+	SetConditions(&status, 2, ConditionTrue(v1beta2.ConditionTypeStalled, ReasonPermanentError, "tenant not found"))
+
+	// then
+	assert.True(t, IsStalled(status, 2))
+	assert.False(t, IsStalled(status, 3), "mapping updated after it stalled should be retried")
+
+	// when
+	SetConditions(&status, 3, ConditionFalse(v1beta2.ConditionTypeStalled, ReasonRetrying, ""))
+
+	// then
+	assert.False(t, IsStalled(status, 3))
+	assert.Equal(t, "tenant not found", status.LastError)
+}
@@ -1,7 +1,7 @@
 package status
 
 import (
-	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 )
 
 type Status = int
@@ -35,7 +35,7 @@ func StateText(status Status) string {
 	return FailedState
 }
 
-func Number(status v1beta1.CompassManagerMappingStatus) Status {
+func Number(status v1beta2.CompassManagerMappingStatus) Status {
 	out := Status(0)
 
 	if status.State == ProcessingState {
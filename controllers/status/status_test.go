@@ -3,7 +3,7 @@ package status
 import (
 	"testing"
 
-	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 )
 
 func Test_stateText(t *testing.T) {
@@ -82,7 +82,7 @@ func Test_stateText(t *testing.T) {
 
 func Test_statusNumber(t *testing.T) {
 	type args struct {
-		status v1beta1.CompassManagerMappingStatus
+		status v1beta2.CompassManagerMappingStatus
 	}
 	tests := []struct {
 		name string
@@ -91,47 +91,47 @@ func Test_statusNumber(t *testing.T) {
 	}{
 		{
 			name: "Should return Empty status number from initially created status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{}},
 			want: Empty,
 		},
 		{
 			name: "Should return Processing status number from Processing status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{State: "Processing"}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{State: "Processing"}},
 			want: Processing,
 		},
 		{
 			name: "Should return Failed status number from Failed status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{State: "Failed"}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{State: "Failed"}},
 			want: Failed,
 		},
 		{
 			name: "Should return Registered status number from Registered status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{Registered: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{Registered: true}},
 			want: Registered,
 		},
 		{
 			name: "Should return Configured status number from Configured status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{Configured: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{Configured: true}},
 			want: Configured,
 		},
 		{
 			name: "Should return  Configured | Registered status number from Configured and Registered status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{Configured: true, Registered: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{Configured: true, Registered: true}},
 			want: Configured | Registered,
 		},
 		{
 			name: "Should return Registered | Failed status number from Registered and Failed status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{State: "Failed", Registered: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{State: "Failed", Registered: true}},
 			want: Registered | Failed,
 		},
 		{
 			name: "Should return Registered | Processing status number from Registered and Processing status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{State: "Processing", Registered: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{State: "Processing", Registered: true}},
 			want: Registered | Processing,
 		},
 		{
 			name: "Should return Configured | Failed status number from Registered and Failed status",
-			args: args{status: v1beta1.CompassManagerMappingStatus{State: "Failed", Configured: true}},
+			args: args{status: v1beta2.CompassManagerMappingStatus{State: "Failed", Configured: true}},
 			want: Configured | Failed,
 		},
 	}
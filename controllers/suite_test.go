@@ -7,13 +7,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 	"github.com/kyma-project/compass-manager/controllers/metrics"
 	"github.com/kyma-project/compass-manager/controllers/mocks"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
 	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
 	. "github.com/onsi/ginkgo/v2" //nolint:revive
 	. "github.com/onsi/gomega"    //nolint:revive
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -60,7 +62,7 @@ var _ = BeforeSuite(func() {
 
 	err = kyma.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	err = v1beta1.AddToScheme(scheme.Scheme)
+	err = v1beta2.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:scheme
@@ -77,7 +79,8 @@ var _ = BeforeSuite(func() {
 
 	requeueTime := time.Second * 5
 	requeueTimeForKubeconfig := time.Second * 5
-	metrics := metrics.NewMetrics()
+	metrics, err := metrics.NewMetrics(metrics.ModePerKyma)
+	Expect(err).ToNot(HaveOccurred())
 
 	cm = NewCompassManagerReconciler(
 		k8sManager,
@@ -88,10 +91,14 @@ var _ = BeforeSuite(func() {
 		requeueTimeForKubeconfig,
 		true,
 		false,
+		false,
 		metrics,
+		NewFailureBackoff(time.Second, time.Minute, 5),
+		DirectorRouting{DefaultBackend: "default"},
+		time.Hour,
 	)
 	k8sClient = k8sManager.GetClient()
-	err = cm.SetupWithManager(k8sManager)
+	err = cm.SetupWithManager(k8sManager, NewControllerOptions(1, 10, 100))
 	Expect(err).ToNot(HaveOccurred())
 
 	Expect(createNamespace(kymaCustomResourceNamespace)).To(Succeed())
@@ -129,45 +136,70 @@ var _ = AfterSuite(func() {
 
 func prepareMockFunctions(c *mocks.Configurator, r *mocks.Registrator) {
 	// It handles `compass-runtime-id-for-migration`
-	compassLabelsRegistered := createCompassRuntimeLabels(map[string]string{LabelShootName: "preregistered", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsRegistered).Return("id-preregistered-incorrect", nil)
+	compassLabelsRegistered := createCompassRuntimeLabels(map[string]string{LabelShootName: "preregistered", LabelKymaName: "preregistered", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsRegistered).Return("id-preregistered-incorrect", nil)
 	// succeeding test case
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-preregistered"), "preregistered-id", "globalAccount").Return(nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-preregistered"), "preregistered-id", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
 	// failing test case
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-preregistered"), "preregistered-id", "globalAccount").Return(errors.New("this shouldn't be called"))
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-preregistered"), "preregistered-id", "globalAccount", v1beta2.AgentConfiguration{}).Return(errors.New("this shouldn't be called"))
 
-	compassLabelsAllGood := createCompassRuntimeLabels(map[string]string{LabelShootName: "all-good", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsAllGood).Return("id-all-good", nil)
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-all-good"), "id-all-good", "globalAccount").Return(nil)
+	compassLabelsAllGood := createCompassRuntimeLabels(map[string]string{LabelShootName: "all-good", LabelKymaName: "all-good", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsAllGood).Return("id-all-good", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-all-good"), "id-all-good", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
 
-	compassLabelsConfigureFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "configure-fails", LabelGlobalAccountID: "globalAccount"})
+	compassLabelsConfigureFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "configure-fails", LabelKymaName: "configure-fails", LabelGlobalAccountID: "globalAccount"})
 	// The first call to ConfigureRuntimeAgent fails, but the second is successful
-	r.On("RegisterInCompass", compassLabelsConfigureFails).Return("id-configure-fails", nil)
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-configure-fails"), "id-configure-fails", "globalAccount").Return(errors.New("error during configuration of Compass Runtime Agent CR")).Once()
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-configure-fails"), "id-configure-fails", "globalAccount").Return(nil).Once()
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsConfigureFails).Return("id-configure-fails", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-configure-fails"), "id-configure-fails", "globalAccount", v1beta2.AgentConfiguration{}).Return(errors.New("error during configuration of Compass Runtime Agent CR")).Once()
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-configure-fails"), "id-configure-fails", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil).Once()
 
-	compassLabelsRegistrationFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "registration-fails", LabelGlobalAccountID: "globalAccount"})
+	compassLabelsRegistrationFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "registration-fails", LabelKymaName: "registration-fails", LabelGlobalAccountID: "globalAccount"})
 	// The first call to RegisterInCompass fails, but the second is successful.
-	r.On("RegisterInCompass", compassLabelsRegistrationFails).Return("", errors.New("error during registration")).Once()
-	r.On("RegisterInCompass", compassLabelsRegistrationFails).Return("registration-fails", nil).Once()
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-registration-fails"), "registration-fails", "globalAccount").Return(nil)
-
-	compassLabelsEmptyKubeconfig := createCompassRuntimeLabels(map[string]string{LabelShootName: "empty-kubeconfig", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsEmptyKubeconfig).Return("id-empty-kubeconfig", nil)
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-empty-kubeconfig"), "id-empty-kubeconfig", "globalAccount").Return(nil)
-
-	compassLabelsDeregistration := createCompassRuntimeLabels(map[string]string{LabelShootName: "unregister-runtime", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsDeregistration).Return("id-unregister-runtime", nil)
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-unregister-runtime"), "id-unregister-runtime", "globalAccount").Return(nil)
-	r.On("DeregisterFromCompass", "id-unregister-runtime", "globalAccount").Return(nil)
-
-	compassLabelsDeregistrationFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "unregister-runtime-fails", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsDeregistrationFails).Return("id-unregister-runtime-fails", nil)
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-unregister-runtime-fails"), "id-unregister-runtime-fails", "globalAccount").Return(nil)
-	r.On("DeregisterFromCompass", "id-unregister-runtime-fails", "globalAccount").Return(errors.New("error during unregistration of the runtime")).Once()
-	r.On("DeregisterFromCompass", "id-unregister-runtime-fails", "globalAccount").Return(nil).Once()
-
-	compassLabelsRefreshToken := createCompassRuntimeLabels(map[string]string{LabelShootName: "refresh-token", LabelGlobalAccountID: "globalAccount"})
-	r.On("RegisterInCompass", compassLabelsRefreshToken).Return("id-refresh-token", nil).Once()
-	c.On("ConfigureCompassRuntimeAgent", []byte("kubeconfig-data-refresh-token"), "id-refresh-token", "globalAccount").Return(nil).Twice()
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsRegistrationFails).Return("", errors.New("error during registration")).Once()
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsRegistrationFails).Return("registration-fails", nil).Once()
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-registration-fails"), "registration-fails", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+
+	compassLabelsEmptyKubeconfig := createCompassRuntimeLabels(map[string]string{LabelShootName: "empty-kubeconfig", LabelKymaName: "empty-kubeconfig", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsEmptyKubeconfig).Return("id-empty-kubeconfig", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-empty-kubeconfig"), "id-empty-kubeconfig", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+
+	compassLabelsDeregistration := createCompassRuntimeLabels(map[string]string{LabelShootName: "unregister-runtime", LabelKymaName: "unregister-runtime", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsDeregistration).Return("id-unregister-runtime", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-unregister-runtime"), "id-unregister-runtime", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+	c.On("DeconfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-unregister-runtime"), v1beta2.AgentConfiguration{}).Return(nil)
+	r.On("DeregisterFromCompass", mock.Anything, "id-unregister-runtime", "globalAccount").Return(nil)
+
+	compassLabelsDeregistrationFails := createCompassRuntimeLabels(map[string]string{LabelShootName: "unregister-runtime-fails", LabelKymaName: "unregister-runtime-fails", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsDeregistrationFails).Return("id-unregister-runtime-fails", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-unregister-runtime-fails"), "id-unregister-runtime-fails", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+	c.On("DeconfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-unregister-runtime-fails"), v1beta2.AgentConfiguration{}).Return(nil)
+	r.On("DeregisterFromCompass", mock.Anything, "id-unregister-runtime-fails", "globalAccount").Return(errors.New("error during unregistration of the runtime")).Once()
+	r.On("DeregisterFromCompass", mock.Anything, "id-unregister-runtime-fails", "globalAccount").Return(nil).Once()
+
+	compassLabelsRefreshToken := createCompassRuntimeLabels(map[string]string{LabelShootName: "refresh-token", LabelKymaName: "refresh-token", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsRefreshToken).Return("id-refresh-token", nil).Once()
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-refresh-token"), "id-refresh-token", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil).Twice()
+
+	compassLabelsDisableModule := createCompassRuntimeLabels(map[string]string{LabelShootName: "disable-module", LabelKymaName: "disable-module", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsDisableModule).Return("id-disable-module", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-disable-module"), "id-disable-module", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+	c.On("DeconfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-disable-module"), v1beta2.AgentConfiguration{}).Return(nil)
+	r.On("DeregisterFromCompass", mock.Anything, "id-disable-module", "globalAccount").Return(nil)
+
+	// The migration to the first target stalls, and succeeds once the target is changed
+	compassLabelsMigrationStalls := createCompassRuntimeLabels(map[string]string{LabelShootName: "migration-stalls", LabelKymaName: "migration-stalls", LabelGlobalAccountID: "globalAccount"})
+	r.On("RegisterInCompass", mock.Anything, "", compassLabelsMigrationStalls).Return("id-migration-stalls", nil)
+	c.On("ConfigureCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-migration-stalls"), "id-migration-stalls", "globalAccount", v1beta2.AgentConfiguration{}).Return(nil)
+	r.On("RegisterInCompass", mock.Anything, "", migrationTargetLabels("migration-stalls", "ga-failing")).Return("", apperrors.BadRequest("invalid Global Account"))
+	r.On("RegisterInCompass", mock.Anything, "", migrationTargetLabels("migration-stalls", "ga-target")).Return("id-migration-target", nil)
+	c.On("VerifyCompassRuntimeAgent", mock.Anything, []byte("kubeconfig-data-migration-stalls"), "id-migration-target", "ga-target", v1beta2.AgentConfiguration{}).Return([]string{}, nil)
+	r.On("IsRuntimeConnected", mock.Anything, "id-migration-target", "ga-target").Return(true, nil)
+	r.On("DeregisterFromCompass", mock.Anything, "id-migration-stalls", "globalAccount").Return(nil)
+}
+
+// migrationTargetLabels matches the labels of the Runtime registered in the migration target Global Account
+func migrationTargetLabels(kymaName, globalAccount string) interface{} {
+	return mock.MatchedBy(func(labels map[string]interface{}) bool {
+		return labels["gardenerClusterName"] == kymaName && labels["global_account_id"] == globalAccount
+	})
 }
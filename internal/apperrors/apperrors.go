@@ -1,6 +1,7 @@
 package apperrors
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -12,6 +13,7 @@ const (
 	ErrCompassDirectorClient ErrComponent = "compass director client"
 	ErrCompassDirector       ErrComponent = "compass director"
 	ErrMpsOAuth2             ErrComponent = "mps oauth2"
+	ErrKymaRuntime           ErrComponent = "kyma runtime"
 )
 
 const (
@@ -21,6 +23,20 @@ const (
 	ErrDirectorRuntimeIDMismatch      ErrReason = "err_director_runtime_id_mismatch"
 	ErrDirectorClientGraphqlizer      ErrReason = "err_director_client_graphqlizer"
 	ErrDirectorRuntimeIDInvalidFormat ErrReason = "err_director_runtime_id_invalid_format"
+	ErrDirectorRequestFailed          ErrReason = "err_director_request_failed"
+	ErrDirectorRuntimeNotFound        ErrReason = "err_director_runtime_not_found"
+	ErrDirectorRuntimeNotUnique       ErrReason = "err_director_runtime_not_unique"
+	ErrDirectorRateLimited            ErrReason = "err_director_rate_limited"
+	ErrDirectorUnknownBackend         ErrReason = "err_director_unknown_backend"
+
+	ErrOAuthTokenRequestFailed ErrReason = "err_oauth_token_request_failed"
+	ErrOAuthInvalidClient      ErrReason = "err_oauth_invalid_client"
+	ErrOAuthInvalidScope       ErrReason = "err_oauth_invalid_scope"
+	ErrOAuthInvalidRequest     ErrReason = "err_oauth_invalid_request"
+
+	ErrKubeconfigNotFound      ErrReason = "err_kubeconfig_not_found"
+	ErrKubeconfigInvalid       ErrReason = "err_kubeconfig_invalid"
+	ErrRuntimeAPIRequestFailed ErrReason = "err_runtime_api_request_failed"
 )
 
 type ErrCode int
@@ -139,3 +155,24 @@ func (ae appError) Reason() ErrReason {
 	}
 	return ae.reason
 }
+
+// IsRetryable returns false if err is an AppError which won't go away when the operation is retried, e.g. the Global Account doesn't exist.
+// Errors other than AppError are considered retryable.
+func IsRetryable(err error) bool {
+	var appErr AppError
+	if !errors.As(err, &appErr) {
+		return true
+	}
+
+	switch appErr.Code() {
+	case CodeBadRequest, CodeForbidden:
+		return false
+	}
+
+	switch appErr.Reason() {
+	case ErrDirectorClientGraphqlizer, ErrDirectorRuntimeIDInvalidFormat, ErrDirectorRuntimeNotUnique, ErrKubeconfigInvalid:
+		return false
+	default:
+		return true
+	}
+}
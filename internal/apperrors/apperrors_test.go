@@ -1,6 +1,8 @@
 package apperrors
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,3 +54,39 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, "Some additional message: error, Some Forbidden apperror, Some pkg err", appendedForbiddenErr.Error())
 	})
 }
+
+func TestIsRetryable(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"internal error": {
+			err:       Internal("error"),
+			retryable: true,
+		},
+		"unauthorized": {
+			err:       BadGateway("unauthorized").SetComponent(ErrCompassDirector),
+			retryable: true,
+		},
+		"wrapped invalid Global Account": {
+			err:       fmt.Errorf("failed to register: %w", InvalidGlobalAccount("tenant not found").SetComponent(ErrCompassDirector)),
+			retryable: false,
+		},
+		"invalid data": {
+			err:       BadRequest("invalid data").SetComponent(ErrCompassDirector),
+			retryable: false,
+		},
+		"Runtime not unique": {
+			err:       Internal("found 2 Runtimes").SetComponent(ErrCompassDirector).SetReason(ErrDirectorRuntimeNotUnique),
+			retryable: false,
+		},
+		"error other than AppError": {
+			err:       errors.New("connection refused"),
+			retryable: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.retryable, IsRetryable(testCase.err))
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package director
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	directorApperrors "github.com/kyma-incubator/compass/components/director/pkg/apperrors"
@@ -10,10 +15,11 @@ import (
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql/graphqlizer"
 	"github.com/kyma-project/compass-manager/internal/apperrors"
 	gql "github.com/kyma-project/compass-manager/internal/graphql"
+	"github.com/kyma-project/compass-manager/internal/logging"
 	"github.com/kyma-project/compass-manager/internal/oauth"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	"github.com/kyma-project/compass-manager/pkg/gqlschema"
 	gcli "github.com/kyma-project/compass-manager/third_party/machinebox/graphql"
-	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -23,32 +29,36 @@ const (
 
 //go:generate mockery --name=Client
 type Client interface {
-	CreateRuntime(config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError)
-	GetRuntime(compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError)
-	GetConnectionToken(compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError)
-	DeleteRuntime(compassID, globalAccount string) apperrors.AppError
+	CreateRuntime(ctx context.Context, config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError)
+	GetRuntime(ctx context.Context, compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError)
+	ListRuntimes(ctx context.Context, labels map[string]string, globalAccount string) ([]graphql.RuntimeExt, apperrors.AppError)
+	SetRuntimeLabel(ctx context.Context, compassID, globalAccount, key, value string) apperrors.AppError
+	GetConnectionToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError)
+	DeleteRuntime(ctx context.Context, compassID, globalAccount string) apperrors.AppError
 }
 
 type directorClient struct {
 	gqlClient     gql.Client
 	queryProvider queryProvider
 	graphqlizer   graphqlizer.Graphqlizer
-	token         oauth.Token
-	oauthClient   oauth.Client
+	tokenSource   oauth.TokenSource
+	rateLimiter   *RateLimiter
 }
 
-func NewDirectorClient(gqlClient gql.Client, oauthClient oauth.Client) Client {
+// NewDirectorClient creates the Director client. Requests are not rate limited when rateLimiter is nil.
+// When tokenSource is nil, requests are sent without the access token, and must be authenticated by the client certificate of gqlClient.
+func NewDirectorClient(gqlClient gql.Client, tokenSource oauth.TokenSource, rateLimiter *RateLimiter) Client {
 	return &directorClient{
 		gqlClient:     gqlClient,
-		oauthClient:   oauthClient,
+		tokenSource:   tokenSource,
+		rateLimiter:   rateLimiter,
 		queryProvider: queryProvider{},
 		graphqlizer:   graphqlizer.Graphqlizer{},
-		token:         oauth.Token{},
 	}
 }
 
-func (cc *directorClient) CreateRuntime(config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
-	log.Infof("Registering Runtime on Director service")
+func (cc *directorClient) CreateRuntime(ctx context.Context, config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
+	logging.FromContext(ctx).Infof("Registering Runtime on Director service")
 
 	if config == nil {
 		return "", apperrors.BadRequest("Cannot register runtime in Director: missing Runtime config")
@@ -68,14 +78,14 @@ func (cc *directorClient) CreateRuntime(config *gqlschema.RuntimeInput, globalAc
 
 	runtimeInput, err := cc.graphqlizer.RuntimeRegisterInputToGQL(directorInput)
 	if err != nil {
-		log.Infof("Failed to create graphQLized Runtime input")
+		logging.FromContext(ctx).Infof("Failed to create graphQLized Runtime input")
 		return "", apperrors.Internalf("Failed to create graphQLized Runtime input: %s", err.Error()).SetComponent(apperrors.ErrCompassDirectorClient).SetReason(apperrors.ErrDirectorClientGraphqlizer)
 	}
 
 	runtimeQuery := cc.queryProvider.createRuntimeMutation(runtimeInput)
 
 	var response CreateRuntimeResponse
-	appErr := cc.executeDirectorGraphQLCall(runtimeQuery, globalAccount, &response, false)
+	appErr := cc.executeDirectorGraphQLCall(ctx, OperationCreateRuntime, runtimeQuery, globalAccount, &response, false)
 	if appErr != nil {
 		return "", appErr.Append("Failed to register runtime in Director. Request failed")
 	}
@@ -90,37 +100,107 @@ func (cc *directorClient) CreateRuntime(config *gqlschema.RuntimeInput, globalAc
 		return "", apperrors.Internal("Failed to register runtime in Director: Received ID is not in UUID format").SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeIDInvalidFormat)
 	}
 
-	log.Infof("Successfully registered Runtime %s in Director for Global Account %s", config.Name, globalAccount)
+	logging.FromContext(ctx).Infof("Successfully registered Runtime %s in Director for Global Account %s", config.Name, globalAccount)
 
 	return response.Result.ID, nil
 }
 
-func (cc *directorClient) GetRuntime(compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
-	log.Infof("Getting Runtime from Director service")
+func (cc *directorClient) GetRuntime(ctx context.Context, compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
+	logging.FromContext(ctx).Infof("Getting Runtime from Director service")
 
 	runtimeQuery := cc.queryProvider.getRuntimeQuery(compassID)
 
 	var response GetRuntimeResponse
-	err := cc.executeDirectorGraphQLCall(runtimeQuery, globalAccount, &response, false)
+	err := cc.executeDirectorGraphQLCall(ctx, OperationGetRuntime, runtimeQuery, globalAccount, &response, true)
 	if err != nil {
 		return graphql.RuntimeExt{}, err.Append("Failed to get runtime %s from Director", compassID)
 	}
+	// Director returns null instead of an error for runtimes that don't exist
 	if response.Result == nil {
-		return graphql.RuntimeExt{}, apperrors.Internalf("Failed to get runtime %s from Director: received nil response.", compassID).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorNilResponse)
+		return graphql.RuntimeExt{}, apperrors.NotFound(fmt.Sprintf("Failed to get runtime %s from Director: runtime not found.", compassID)).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeNotFound)
 	}
 	if response.Result.ID != compassID {
 		return graphql.RuntimeExt{}, apperrors.Internalf("Failed to get runtime %s from Director: received unexpected RuntimeID", compassID).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeIDMismatch)
 	}
 
-	log.Infof("Successfully got Runtime %s from Director for Global Account %s", compassID, globalAccount)
+	logging.FromContext(ctx).Infof("Successfully got Runtime %s from Director for Global Account %s", compassID, globalAccount)
 	return *response.Result, nil
 }
 
-func (cc *directorClient) GetConnectionToken(compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
+// ListRuntimes returns Runtimes which have all the given labels set to the given values
+func (cc *directorClient) ListRuntimes(ctx context.Context, labels map[string]string, globalAccount string) ([]graphql.RuntimeExt, apperrors.AppError) {
+	logging.FromContext(ctx).Infof("Listing Runtimes from Director service")
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	filters := make([]string, 0, len(labels))
+	for _, key := range keys {
+		filter, err := cc.graphqlizer.LabelFilterToGQL(graphql.LabelFilter{Key: key, Query: ptr(strconv.Quote(labels[key]))})
+		if err != nil {
+			return nil, apperrors.Internalf("Failed to create graphQLized label filter: %s", err.Error()).SetComponent(apperrors.ErrCompassDirectorClient).SetReason(apperrors.ErrDirectorClientGraphqlizer)
+		}
+		filters = append(filters, filter)
+	}
+
+	var runtimes []graphql.RuntimeExt
+	var cursor string
+	for {
+		runtimesQuery := cc.queryProvider.listRuntimesQuery(strings.Join(filters, ", "), cursor)
+
+		var response ListRuntimesResponse
+		err := cc.executeDirectorGraphQLCall(ctx, OperationListRuntimes, runtimesQuery, globalAccount, &response, false)
+		if err != nil {
+			return nil, err.Append("Failed to list runtimes from Director")
+		}
+		// Nil check is necessary due to GraphQL client not checking response code
+		if response.Result == nil {
+			return nil, apperrors.Internal("Failed to list runtimes from Director: received nil response.").SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorNilResponse)
+		}
+
+		for _, runtime := range response.Result.Data {
+			if runtime != nil {
+				runtimes = append(runtimes, *runtime)
+			}
+		}
+
+		if response.Result.PageInfo == nil || !response.Result.PageInfo.HasNextPage {
+			break
+		}
+		cursor = string(response.Result.PageInfo.EndCursor)
+	}
+
+	logging.FromContext(ctx).Infof("Successfully listed %d Runtimes from Director for Global Account %s", len(runtimes), globalAccount)
+	return runtimes, nil
+}
+
+func (cc *directorClient) SetRuntimeLabel(ctx context.Context, compassID, globalAccount, key, value string) apperrors.AppError {
+	logging.FromContext(ctx).Infof("Setting label %s of Runtime %s in Director service", key, compassID)
+
+	labelQuery := cc.queryProvider.setRuntimeLabelMutation(compassID, key, value)
+
+	var response SetRuntimeLabelResponse
+	err := cc.executeDirectorGraphQLCall(ctx, OperationSetRuntimeLabel, labelQuery, globalAccount, &response, true)
+	if err != nil {
+		return err.Append("Failed to set label %s of runtime %s in Director", key, compassID)
+	}
+	// Nil check is necessary due to GraphQL client not checking response code
+	if response.Result == nil {
+		return apperrors.Internalf("Failed to set label %s of runtime %s in Director: received nil response.", key, compassID).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorNilResponse)
+	}
+
+	logging.FromContext(ctx).Infof("Successfully set label %s of Runtime %s in Director for Global Account %s", key, compassID, globalAccount)
+	return nil
+}
+
+func (cc *directorClient) GetConnectionToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
 	runtimeQuery := cc.queryProvider.requestOneTimeTokenMutation(compassID)
 
 	var response OneTimeTokenResponse
-	err := cc.executeDirectorGraphQLCall(runtimeQuery, globalAccount, &response, false)
+	err := cc.executeDirectorGraphQLCall(ctx, OperationGetConnectionToken, runtimeQuery, globalAccount, &response, false)
 	if err != nil {
 		return graphql.OneTimeTokenForRuntimeExt{}, err.Append("Failed to get OneTimeToken for Runtime %s in Director", compassID)
 	}
@@ -129,19 +209,19 @@ func (cc *directorClient) GetConnectionToken(compassID, globalAccount string) (g
 		return graphql.OneTimeTokenForRuntimeExt{}, apperrors.Internalf("Failed to get OneTimeToken for Runtime %s in Director: received nil response.", compassID).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorNilResponse)
 	}
 
-	log.Infof("Received OneTimeToken for Runtime %s in Director for Global Account %s", compassID, globalAccount)
+	logging.FromContext(ctx).Infof("Received OneTimeToken for Runtime %s in Director for Global Account %s", compassID, globalAccount)
 
 	return *response.Result, nil
 }
 
-func (cc *directorClient) DeleteRuntime(compassID, globalAccount string) apperrors.AppError {
+func (cc *directorClient) DeleteRuntime(ctx context.Context, compassID, globalAccount string) apperrors.AppError {
 	runtimeQuery := cc.queryProvider.deleteRuntimeMutation(compassID)
 
 	var response DeleteRuntimeResponse
-	err := cc.executeDirectorGraphQLCall(runtimeQuery, globalAccount, &response, true)
+	err := cc.executeDirectorGraphQLCall(ctx, OperationDeleteRuntime, runtimeQuery, globalAccount, &response, true)
 	if err != nil {
 		if err.Cause() == apperrors.RuntimeNotFound {
-			log.Infof("Runtime %s in Director for tenant %s was previously deleted", compassID, globalAccount)
+			logging.FromContext(ctx).Infof("Runtime %s in Director for tenant %s was previously deleted", compassID, globalAccount)
 			return nil
 		}
 		return err.Append("Failed to unregister runtime %s in Director", compassID)
@@ -155,46 +235,100 @@ func (cc *directorClient) DeleteRuntime(compassID, globalAccount string) apperro
 		return apperrors.Internalf("Failed to unregister runtime %s in Director: received unexpected RuntimeID.", compassID).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRuntimeIDMismatch)
 	}
 
-	log.Infof("Successfully unregistered Runtime %s in Director for tenant %s", compassID, globalAccount)
+	logging.FromContext(ctx).Infof("Successfully unregistered Runtime %s in Director for tenant %s", compassID, globalAccount)
 
 	return nil
 }
 
-func (cc *directorClient) getToken() apperrors.AppError {
-	token, err := cc.oauthClient.GetAuthorizationToken()
-	if err != nil {
-		return err.Append("Error while obtaining token")
-	}
+// executeDirectorGraphQLCall sends the query to Director, traced as the span of the GraphQL operation.
+// When Director rejects the token, the query is sent once more with a new token.
+func (cc *directorClient) executeDirectorGraphQLCall(ctx context.Context, operation, directorQuery string, globalAccount string, response interface{}, gracefulUnregistration bool) apperrors.AppError {
+	ctx, span := tracing.Start(ctx, "Director "+operation,
+		tracing.AttributeGraphQLOperation.String(operation),
+		tracing.AttributeTenant.String(globalAccount),
+		tracing.AttributeDirectorBackend.String(BackendFromContext(ctx)))
 
-	if token.EmptyOrExpired() {
-		return apperrors.Internal("Obtained empty or expired token")
+	appErr := cc.executeDirectorGraphQLCallWithToken(ctx, directorQuery, globalAccount, response, gracefulUnregistration)
+	if appErr != nil {
+		tracing.End(span, appErr)
+		return appErr
 	}
-
-	cc.token = token
+	span.End()
 	return nil
 }
 
-func (cc *directorClient) executeDirectorGraphQLCall(directorQuery string, globalAccount string, response interface{}, gracefulUnregistration bool) apperrors.AppError {
-	if cc.token.EmptyOrExpired() {
-		log.Infof("Refreshing token to access Director Service")
-		if err := cc.getToken(); err != nil {
-			return err
+// executeDirectorGraphQLCallWithToken sends the query with the cached token, and retries it with a new token when Director rejects it
+func (cc *directorClient) executeDirectorGraphQLCallWithToken(ctx context.Context, directorQuery string, globalAccount string, response interface{}, gracefulUnregistration bool) apperrors.AppError {
+	if cc.tokenSource == nil {
+		err := cc.doDirectorGraphQLCall(ctx, oauth.Token{}, directorQuery, globalAccount, response, gracefulUnregistration)
+		return toDirectorAppError(err, gracefulUnregistration)
+	}
+
+	token, appErr := cc.tokenSource.Token(ctx)
+	if appErr != nil {
+		return appErr
+	}
+
+	err := cc.doDirectorGraphQLCall(ctx, token, directorQuery, globalAccount, response, gracefulUnregistration)
+	if isUnauthorized(err) {
+		logging.FromContext(ctx).Infof("Director rejected the access token, retrying with a new one")
+		cc.tokenSource.Invalidate(token)
+		token, appErr = cc.tokenSource.Token(ctx)
+		if appErr != nil {
+			return appErr
 		}
+		err = cc.doDirectorGraphQLCall(ctx, token, directorQuery, globalAccount, response, gracefulUnregistration)
+	}
+
+	return toDirectorAppError(err, gracefulUnregistration)
+}
+
+// toDirectorAppError converts the error returned by the GraphQL client to apperrors.AppError
+func toDirectorAppError(err error, gracefulUnregistration bool) apperrors.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var clientErr apperrors.AppError
+	if errors.As(err, &clientErr) {
+		return clientErr
+	}
+
+	var egErr gcli.ExtendedError
+	if errors.As(err, &egErr) {
+		return mapDirectorErrorToProvisionerError(egErr, gracefulUnregistration).Append("Failed to execute GraphQL request to Director")
+	}
+	return apperrors.Internalf("Failed to execute GraphQL request to Director: %v", err).SetComponent(apperrors.ErrCompassDirector).SetReason(apperrors.ErrDirectorRequestFailed)
+}
+
+func (cc *directorClient) doDirectorGraphQLCall(ctx context.Context, token oauth.Token, directorQuery string, globalAccount string, response interface{}, gracefulUnregistration bool) error {
+	if err := cc.rateLimiter.Wait(ctx, globalAccount); err != nil {
+		return apperrors.Internalf("Failed to wait for the Director rate limiter: %v", err).SetComponent(apperrors.ErrCompassDirectorClient).SetReason(apperrors.ErrDirectorRateLimited)
 	}
 
 	req := gcli.NewRequest(directorQuery)
-	req.Header.Set(AuthorizationHeader, fmt.Sprintf("Bearer %s", cc.token.AccessToken))
+	if token.AccessToken != "" {
+		req.Header.Set(AuthorizationHeader, fmt.Sprintf("Bearer %s", token.AccessToken))
+	}
 	req.Header.Set(TenantHeader, globalAccount)
+	tracing.InjectHeaders(ctx, req.Header)
 
-	if err := cc.gqlClient.Do(req, response, gracefulUnregistration); err != nil {
-		var egErr gcli.ExtendedError
-		if errors.As(err, &egErr) {
-			return mapDirectorErrorToProvisionerError(egErr, gracefulUnregistration).Append("Failed to execute GraphQL request to Director")
-		}
-		return apperrors.Internalf("Failed to execute GraphQL request to Director: %v", err)
+	return cc.gqlClient.Do(ctx, req, response, gracefulUnregistration)
+}
+
+// isUnauthorized returns true if Director rejected the access token
+func isUnauthorized(err error) bool {
+	var statusErr gcli.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized
 	}
 
-	return nil
+	var egErr gcli.ExtendedError
+	if errors.As(err, &egErr) {
+		errorCode, ok := egErr.Extensions()["error_code"].(float64)
+		return ok && directorApperrors.ErrorType(errorCode) == directorApperrors.Unauthorized
+	}
+	return false
 }
 
 func mapDirectorErrorToProvisionerError(egErr gcli.ExtendedError, gracefulUnregistration bool) apperrors.AppError {
@@ -232,3 +366,7 @@ func mapDirectorErrorToProvisionerError(egErr gcli.ExtendedError, gracefulUnregi
 
 	return err.SetComponent(apperrors.ErrCompassDirector).SetReason(reason)
 }
+
+func ptr[T any](value T) *T {
+	return &value
+}
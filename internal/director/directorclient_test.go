@@ -1,8 +1,10 @@
 package director
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -10,13 +12,20 @@ import (
 	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
 	"github.com/kyma-project/compass-manager/internal/apperrors"
 	gql "github.com/kyma-project/compass-manager/internal/graphql"
+	gqlmocks "github.com/kyma-project/compass-manager/internal/graphql/mocks"
 	"github.com/kyma-project/compass-manager/internal/oauth"
 	oauthmocks "github.com/kyma-project/compass-manager/internal/oauth/mocks"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	"github.com/kyma-project/compass-manager/internal/util"
 	"github.com/kyma-project/compass-manager/pkg/gqlschema"
 	gcli "github.com/kyma-project/compass-manager/third_party/machinebox/graphql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const (
@@ -40,7 +49,38 @@ const (
 
 	expectedGetRuntimeQuery = `query {
     result: runtime(id: "4366e452-2ffb-435d-abbd-81cf5d3965c9") {
-         id name description labels
+         id name description labels status { condition }
+}}`
+
+	expectedListRuntimesQuery = `query {
+	result: runtimes(filter: [{
+		key: "broker_instance_id",
+		query: "\"instance\"",
+	}, {
+		key: "gardenerClusterName",
+		query: "\"shoot\"",
+	}], first: 200) {
+		data { id name description labels }
+		pageInfo { endCursor hasNextPage }
+		totalCount
+}}`
+
+	expectedListRuntimesNextPageQuery = `query {
+	result: runtimes(filter: [{
+		key: "broker_instance_id",
+		query: "\"instance\"",
+	}, {
+		key: "gardenerClusterName",
+		query: "\"shoot\"",
+	}], first: 200, after: "next-page") {
+		data { id name description labels }
+		pageInfo { endCursor hasNextPage }
+		totalCount
+}}`
+
+	expectedSetRuntimeLabelQuery = `mutation {
+	result: setRuntimeLabel(runtimeID: "4366e452-2ffb-435d-abbd-81cf5d3965c9", key: "broker_plan_name", value: "azure") {
+		key value
 }}`
 
 	expectedDeleteRuntimeQuery = `mutation {
@@ -90,12 +130,12 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.NoError(t, err)
@@ -110,12 +150,12 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -130,12 +170,12 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(expiredToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(expiredToken, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -145,12 +185,12 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 	t.Run("Should not register Runtime and return error when the client fails to get an access token for Director", func(t *testing.T) {
 		// given
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(oauth.Token{}, apperrors.Internal("Failed token error"))
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(oauth.Token{}, apperrors.Internal("Failed token error"))
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -182,12 +222,12 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -202,7 +242,7 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
 		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
 			cfg, ok := r.(*CreateRuntimeResponse)
@@ -211,10 +251,10 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 			cfg.Result = nil
 		})
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -229,7 +269,7 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
 		gqlClient := gql.NewQueryAssertClient(t, errors.New("error"), []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
 			cfg, ok := r.(*CreateRuntimeResponse)
@@ -238,10 +278,10 @@ func TestDirectorClient_RuntimeRegistering(t *testing.T) {
 			cfg.Result = nil
 		})
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedRuntimeID, err := configClient.CreateRuntime(runtimeInput, globalAccountValue)
+		receivedRuntimeID, err := configClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -276,12 +316,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.NoError(t, err)
@@ -295,12 +335,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(emptyToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(emptyToken, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -314,12 +354,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(expiredToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(expiredToken, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -328,12 +368,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 	t.Run("Should not unregister Runtime and return error when the client fails to get an access token for Director", func(t *testing.T) {
 		// given
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(oauth.Token{}, apperrors.Internal("Failed token error"))
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(oauth.Token{}, apperrors.Internal("Failed token error"))
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -348,7 +388,7 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
 		// given
 		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
@@ -358,10 +398,10 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 			cfg.Result = nil
 		})
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -382,12 +422,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -416,12 +456,12 @@ func TestDirectorClient_RuntimeUnregistering(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(validToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(validToken, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		err := configClient.DeleteRuntime(compassTestingID, globalAccountValue)
+		err := configClient.DeleteRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
@@ -457,12 +497,12 @@ func TestDirectorClient_GetConnectionToken(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedOneTimeToken, err := configClient.GetConnectionToken(compassTestingID, globalAccountValue)
+		receivedOneTimeToken, err := configClient.GetConnectionToken(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.NoError(t, err)
@@ -471,6 +511,36 @@ func TestDirectorClient_GetConnectionToken(t *testing.T) {
 		assert.Equal(t, connectorURL, receivedOneTimeToken.ConnectorURL)
 	})
 
+	t.Run("Should retry with a new Oauth Token when Director rejects the token", func(t *testing.T) {
+		// given
+		rejectedRequest := gcli.NewRequest(expectedOneTimeTokenQuery)
+		rejectedRequest.Header.Set(AuthorizationHeader, "Bearer rejected")
+		rejectedRequest.Header.Set(TenantHeader, globalAccountValue)
+
+		gqlClient := &gqlmocks.Client{}
+		gqlClient.On("Do", mock.Anything, rejectedRequest, mock.Anything, false).Return(gcli.StatusCodeError{StatusCode: http.StatusUnauthorized}).Once()
+		gqlClient.On("Do", mock.Anything, expectedRequest, mock.Anything, false).Run(func(args mock.Arguments) {
+			args.Get(2).(*OneTimeTokenResponse).Result = &graphql.OneTimeTokenForRuntimeExt{ //nolint:forcetypeassert
+				OneTimeTokenForRuntime: graphql.OneTimeTokenForRuntime{TokenWithURL: graphql.TokenWithURL{Token: oneTimeToken, ConnectorURL: connectorURL}},
+			}
+		}).Return(nil).Once()
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(oauth.Token{AccessToken: "rejected", Expiration: futureExpirationTime}, nil).Once()
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(oauth.Token{AccessToken: validTokenValue, Expiration: futureExpirationTime}, nil).Once()
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		receivedOneTimeToken, err := configClient.GetConnectionToken(context.Background(), compassTestingID, globalAccountValue)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, oneTimeToken, receivedOneTimeToken.Token)
+		gqlClient.AssertExpectations(t)
+		mockedOAuthClient.AssertExpectations(t)
+	})
+
 	t.Run("Should return error when Oauth Token is empty", func(t *testing.T) {
 		// given
 		token := oauth.Token{
@@ -479,12 +549,12 @@ func TestDirectorClient_GetConnectionToken(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedOneTimeToken, err := configClient.GetConnectionToken(compassTestingID, globalAccountValue)
+		receivedOneTimeToken, err := configClient.GetConnectionToken(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.Error(t, err)
@@ -499,12 +569,12 @@ func TestDirectorClient_GetConnectionToken(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedOneTimeToken, err := configClient.GetConnectionToken(compassTestingID, globalAccountValue)
+		receivedOneTimeToken, err := configClient.GetConnectionToken(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.Error(t, err)
@@ -525,12 +595,12 @@ func TestDirectorClient_GetConnectionToken(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		receivedOneTimeToken, err := configClient.GetConnectionToken(compassTestingID, globalAccountValue)
+		receivedOneTimeToken, err := configClient.GetConnectionToken(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.Error(t, err)
@@ -565,12 +635,12 @@ func TestDirectorClient_GetRuntime(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		runtime, err := configClient.GetRuntime(compassTestingID, globalAccountValue)
+		runtime, err := configClient.GetRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.NoError(t, err)
@@ -586,19 +656,19 @@ func TestDirectorClient_GetRuntime(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(emptyToken, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(emptyToken, nil)
 
-		configClient := NewDirectorClient(nil, mockedOAuthClient)
+		configClient := NewDirectorClient(nil, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		runtime, err := configClient.GetRuntime(compassTestingID, globalAccountValue)
+		runtime, err := configClient.GetRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		assert.Error(t, err)
 		assert.Empty(t, runtime)
 	})
 
-	t.Run("should return error when Director returns nil response", func(t *testing.T) {
+	t.Run("should return not found error when Director returns nil response", func(t *testing.T) {
 		// given
 		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
 			cfg, ok := r.(*GetRuntimeResponse)
@@ -613,15 +683,16 @@ func TestDirectorClient_GetRuntime(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		runtime, err := configClient.GetRuntime(compassTestingID, globalAccountValue)
+		runtime, err := configClient.GetRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.Error(t, err)
+		assert.Equal(t, apperrors.RuntimeNotFound, err.Cause())
 		assert.Empty(t, runtime)
 	})
 
@@ -640,12 +711,12 @@ func TestDirectorClient_GetRuntime(t *testing.T) {
 		}
 
 		mockedOAuthClient := &oauthmocks.Client{}
-		mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-		configClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 		// when
-		runtime, err := configClient.GetRuntime(compassTestingID, globalAccountValue)
+		runtime, err := configClient.GetRuntime(context.Background(), compassTestingID, globalAccountValue)
 
 		// then
 		require.Error(t, err)
@@ -653,6 +724,154 @@ func TestDirectorClient_GetRuntime(t *testing.T) {
 	})
 }
 
+func TestDirectorClient_ListRuntimes(t *testing.T) {
+	labels := map[string]string{
+		"gardenerClusterName": "shoot",
+		"broker_instance_id":  "instance",
+	}
+
+	token := oauth.Token{
+		AccessToken: validTokenValue,
+		Expiration:  futureExpirationTime,
+	}
+
+	newRequest := func(query string) *gcli.Request {
+		request := gcli.NewRequest(query)
+		request.Header.Set(AuthorizationHeader, fmt.Sprintf("Bearer %s", validTokenValue))
+		request.Header.Set(TenantHeader, globalAccountValue)
+		return request
+	}
+
+	t.Run("should return Runtimes from all pages", func(t *testing.T) {
+		// given
+		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{newRequest(expectedListRuntimesQuery), newRequest(expectedListRuntimesNextPageQuery)},
+			func(t *testing.T, r interface{}) {
+				cfg, ok := r.(*ListRuntimesResponse)
+				require.True(t, ok)
+				cfg.Result = &graphql.RuntimePageExt{
+					RuntimePage: graphql.RuntimePage{PageInfo: &graphql.PageInfo{EndCursor: "next-page", HasNextPage: true}},
+					Data:        []*graphql.RuntimeExt{{Runtime: graphql.Runtime{ID: compassTestingID}}},
+				}
+			},
+			func(t *testing.T, r interface{}) {
+				cfg, ok := r.(*ListRuntimesResponse)
+				require.True(t, ok)
+				cfg.Result = &graphql.RuntimePageExt{
+					RuntimePage: graphql.RuntimePage{PageInfo: &graphql.PageInfo{}},
+					Data:        []*graphql.RuntimeExt{{Runtime: graphql.Runtime{ID: "other-id"}}},
+				}
+			})
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		runtimes, err := configClient.ListRuntimes(context.Background(), labels, globalAccountValue)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, runtimes, 2)
+		assert.Equal(t, compassTestingID, runtimes[0].ID)
+		assert.Equal(t, "other-id", runtimes[1].ID)
+	})
+
+	t.Run("should return error when Director returns nil response", func(t *testing.T) {
+		// given
+		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{newRequest(expectedListRuntimesQuery)}, func(t *testing.T, r interface{}) {
+			cfg, ok := r.(*ListRuntimesResponse)
+			require.True(t, ok)
+			assert.Empty(t, cfg.Result)
+		})
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		runtimes, err := configClient.ListRuntimes(context.Background(), labels, globalAccountValue)
+
+		// then
+		require.Error(t, err)
+		assert.Empty(t, runtimes)
+	})
+}
+
+func TestDirectorClient_SetRuntimeLabel(t *testing.T) {
+	expectedRequest := gcli.NewRequest(expectedSetRuntimeLabelQuery)
+	expectedRequest.Header.Set(AuthorizationHeader, fmt.Sprintf("Bearer %s", validTokenValue))
+	expectedRequest.Header.Set(TenantHeader, globalAccountValue)
+
+	token := oauth.Token{
+		AccessToken: validTokenValue,
+		Expiration:  futureExpirationTime,
+	}
+
+	t.Run("should set Runtime label", func(t *testing.T) {
+		// given
+		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
+			cfg, ok := r.(*SetRuntimeLabelResponse)
+			require.True(t, ok)
+			assert.Empty(t, cfg.Result)
+			cfg.Result = &graphql.Label{Key: "broker_plan_name", Value: "azure"}
+		})
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		err := configClient.SetRuntimeLabel(context.Background(), compassTestingID, globalAccountValue, "broker_plan_name", "azure")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should return error when Director returns nil response", func(t *testing.T) {
+		// given
+		gqlClient := gql.NewQueryAssertClient(t, nil, []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
+			cfg, ok := r.(*SetRuntimeLabelResponse)
+			require.True(t, ok)
+			assert.Empty(t, cfg.Result)
+		})
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		err := configClient.SetRuntimeLabel(context.Background(), compassTestingID, globalAccountValue, "broker_plan_name", "azure")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.ErrDirectorNilResponse, err.Reason())
+	})
+
+	t.Run("should return error when Director fails to set label", func(t *testing.T) {
+		// given
+		gqlClient := gql.NewQueryAssertClient(t, errors.New("error"), []*gcli.Request{expectedRequest}, func(t *testing.T, r interface{}) {
+			cfg, ok := r.(*SetRuntimeLabelResponse)
+			require.True(t, ok)
+			assert.Empty(t, cfg.Result)
+		})
+
+		mockedOAuthClient := &oauthmocks.Client{}
+		mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
+
+		configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+		// when
+		err := configClient.SetRuntimeLabel(context.Background(), compassTestingID, globalAccountValue, "broker_plan_name", "azure")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
 type testGraphQLError struct {
 	Message         string
 	ErrorExtensions map[string]interface{}
@@ -794,12 +1013,12 @@ func TestDirectorClient_MapDirectorErrors(t *testing.T) {
 			gqlClient := gql.NewQueryAssertClient(t, directorError, []*gcli.Request{expectedRequest})
 
 			mockedOAuthClient := &oauthmocks.Client{}
-			mockedOAuthClient.On("GetAuthorizationToken").Return(token, nil)
+			mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(token, nil)
 
-			directorClient := NewDirectorClient(gqlClient, mockedOAuthClient)
+			directorClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
 
 			// when
-			_, err := directorClient.CreateRuntime(runtimeInput, globalAccountValue)
+			_, err := directorClient.CreateRuntime(context.Background(), runtimeInput, globalAccountValue)
 
 			// then
 			require.Error(t, err)
@@ -809,3 +1028,41 @@ func TestDirectorClient_MapDirectorErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectorClient_Tracing(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// given
+	var traceparent string
+	gqlClient := &gqlmocks.Client{}
+	gqlClient.On("Do", mock.Anything, mock.Anything, mock.Anything, true).Run(func(args mock.Arguments) {
+		traceparent = args.Get(1).(*gcli.Request).Header.Get("traceparent")                                     //nolint:forcetypeassert
+		args.Get(2).(*SetRuntimeLabelResponse).Result = &graphql.Label{Key: "broker_plan_name", Value: "azure"} //nolint:forcetypeassert
+	}).Return(nil)
+
+	mockedOAuthClient := &oauthmocks.Client{}
+	mockedOAuthClient.On("GetAuthorizationToken", mock.Anything).Return(oauth.Token{AccessToken: validTokenValue, Expiration: futureExpirationTime}, nil)
+
+	configClient := NewDirectorClient(gqlClient, oauth.NewTokenSource(mockedOAuthClient, 0), nil)
+
+	// when
+	appErr := configClient.SetRuntimeLabel(ContextWithBackend(context.Background(), "eu"), compassTestingID, globalAccountValue, "broker_plan_name", "azure")
+
+	// then
+	require.NoError(t, appErr)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "Director setRuntimeLabel", spans[0].Name())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		tracing.AttributeGraphQLOperation.String("setRuntimeLabel"),
+		tracing.AttributeTenant.String(globalAccountValue),
+		tracing.AttributeDirectorBackend.String("eu"),
+	}, spans[0].Attributes())
+	assert.Contains(t, traceparent, spans[0].SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, spans[0].SpanContext().SpanID().String())
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	graphql "github.com/kyma-incubator/compass/components/director/pkg/graphql"
 	apperrors "github.com/kyma-project/compass-manager/internal/apperrors"
 	gqlschema "github.com/kyma-project/compass-manager/pkg/gqlschema"
@@ -14,23 +16,23 @@ type Client struct {
 	mock.Mock
 }
 
-// CreateRuntime provides a mock function with given fields: config, globalAccount
-func (_m *Client) CreateRuntime(config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
-	ret := _m.Called(config, globalAccount)
+// CreateRuntime provides a mock function with given fields: ctx, config, globalAccount
+func (_m *Client) CreateRuntime(ctx context.Context, config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
+	ret := _m.Called(ctx, config, globalAccount)
 
 	var r0 string
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(0).(func(*gqlschema.RuntimeInput, string) (string, apperrors.AppError)); ok {
-		return rf(config, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, *gqlschema.RuntimeInput, string) (string, apperrors.AppError)); ok {
+		return rf(ctx, config, globalAccount)
 	}
-	if rf, ok := ret.Get(0).(func(*gqlschema.RuntimeInput, string) string); ok {
-		r0 = rf(config, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, *gqlschema.RuntimeInput, string) string); ok {
+		r0 = rf(ctx, config, globalAccount)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(*gqlschema.RuntimeInput, string) apperrors.AppError); ok {
-		r1 = rf(config, globalAccount)
+	if rf, ok := ret.Get(1).(func(context.Context, *gqlschema.RuntimeInput, string) apperrors.AppError); ok {
+		r1 = rf(ctx, config, globalAccount)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -40,13 +42,13 @@ func (_m *Client) CreateRuntime(config *gqlschema.RuntimeInput, globalAccount st
 	return r0, r1
 }
 
-// DeleteRuntime provides a mock function with given fields: compassID, globalAccount
-func (_m *Client) DeleteRuntime(compassID string, globalAccount string) apperrors.AppError {
-	ret := _m.Called(compassID, globalAccount)
+// DeleteRuntime provides a mock function with given fields: ctx, compassID, globalAccount
+func (_m *Client) DeleteRuntime(ctx context.Context, compassID string, globalAccount string) apperrors.AppError {
+	ret := _m.Called(ctx, compassID, globalAccount)
 
 	var r0 apperrors.AppError
-	if rf, ok := ret.Get(0).(func(string, string) apperrors.AppError); ok {
-		r0 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) apperrors.AppError); ok {
+		r0 = rf(ctx, compassID, globalAccount)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(apperrors.AppError)
@@ -56,23 +58,23 @@ func (_m *Client) DeleteRuntime(compassID string, globalAccount string) apperror
 	return r0
 }
 
-// GetConnectionToken provides a mock function with given fields: compassID, globalAccount
-func (_m *Client) GetConnectionToken(compassID string, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
-	ret := _m.Called(compassID, globalAccount)
+// GetConnectionToken provides a mock function with given fields: ctx, compassID, globalAccount
+func (_m *Client) GetConnectionToken(ctx context.Context, compassID string, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
+	ret := _m.Called(ctx, compassID, globalAccount)
 
 	var r0 graphql.OneTimeTokenForRuntimeExt
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(0).(func(string, string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError)); ok {
-		return rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError)); ok {
+		return rf(ctx, compassID, globalAccount)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) graphql.OneTimeTokenForRuntimeExt); ok {
-		r0 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) graphql.OneTimeTokenForRuntimeExt); ok {
+		r0 = rf(ctx, compassID, globalAccount)
 	} else {
 		r0 = ret.Get(0).(graphql.OneTimeTokenForRuntimeExt)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) apperrors.AppError); ok {
-		r1 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) apperrors.AppError); ok {
+		r1 = rf(ctx, compassID, globalAccount)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -82,23 +84,23 @@ func (_m *Client) GetConnectionToken(compassID string, globalAccount string) (gr
 	return r0, r1
 }
 
-// GetRuntime provides a mock function with given fields: compassID, globalAccount
-func (_m *Client) GetRuntime(compassID string, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
-	ret := _m.Called(compassID, globalAccount)
+// GetRuntime provides a mock function with given fields: ctx, compassID, globalAccount
+func (_m *Client) GetRuntime(ctx context.Context, compassID string, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
+	ret := _m.Called(ctx, compassID, globalAccount)
 
 	var r0 graphql.RuntimeExt
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(0).(func(string, string) (graphql.RuntimeExt, apperrors.AppError)); ok {
-		return rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (graphql.RuntimeExt, apperrors.AppError)); ok {
+		return rf(ctx, compassID, globalAccount)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) graphql.RuntimeExt); ok {
-		r0 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) graphql.RuntimeExt); ok {
+		r0 = rf(ctx, compassID, globalAccount)
 	} else {
 		r0 = ret.Get(0).(graphql.RuntimeExt)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) apperrors.AppError); ok {
-		r1 = rf(compassID, globalAccount)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) apperrors.AppError); ok {
+		r1 = rf(ctx, compassID, globalAccount)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -108,6 +110,50 @@ func (_m *Client) GetRuntime(compassID string, globalAccount string) (graphql.Ru
 	return r0, r1
 }
 
+// ListRuntimes provides a mock function with given fields: ctx, labels, globalAccount
+func (_m *Client) ListRuntimes(ctx context.Context, labels map[string]string, globalAccount string) ([]graphql.RuntimeExt, apperrors.AppError) {
+	ret := _m.Called(ctx, labels, globalAccount)
+
+	var r0 []graphql.RuntimeExt
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string, string) ([]graphql.RuntimeExt, apperrors.AppError)); ok {
+		return rf(ctx, labels, globalAccount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string, string) []graphql.RuntimeExt); ok {
+		r0 = rf(ctx, labels, globalAccount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]graphql.RuntimeExt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, map[string]string, string) apperrors.AppError); ok {
+		r1 = rf(ctx, labels, globalAccount)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SetRuntimeLabel provides a mock function with given fields: ctx, compassID, globalAccount, key, value
+func (_m *Client) SetRuntimeLabel(ctx context.Context, compassID string, globalAccount string, key string, value string) apperrors.AppError {
+	ret := _m.Called(ctx, compassID, globalAccount, key, value)
+
+	var r0 apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) apperrors.AppError); ok {
+		r0 = rf(ctx, compassID, globalAccount, key, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(apperrors.AppError)
+		}
+	}
+
+	return r0
+}
+
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClient(t interface {
@@ -12,6 +12,14 @@ type GetRuntimeResponse struct {
 	Result *graphql.RuntimeExt `json:"result"`
 }
 
+type ListRuntimesResponse struct {
+	Result *graphql.RuntimePageExt `json:"result"`
+}
+
+type SetRuntimeLabelResponse struct {
+	Result *graphql.Label `json:"result"`
+}
+
 type DeleteRuntimeResponse struct {
 	Result *graphql.Runtime `json:"result"`
 }
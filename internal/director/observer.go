@@ -0,0 +1,81 @@
+package director
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/pkg/gqlschema"
+)
+
+// Director operations are named after their GraphQL operations, both in the traces and in the metrics
+const (
+	OperationCreateRuntime      = "registerRuntime"
+	OperationGetRuntime         = "runtime"
+	OperationListRuntimes       = "runtimes"
+	OperationSetRuntimeLabel    = "setRuntimeLabel"
+	OperationGetConnectionToken = "requestOneTimeTokenForRuntime"
+	OperationDeleteRuntime      = "unregisterRuntime"
+)
+
+// RequestObserver is notified about every finished Director operation, e.g. to record its latency and errors as metrics.
+// The context of the operation carries the name of the Director backend, see BackendFromContext.
+type RequestObserver interface {
+	ObserveDirectorRequest(ctx context.Context, operation string, duration time.Duration, err apperrors.AppError)
+}
+
+type observedClient struct {
+	client   Client
+	observer RequestObserver
+}
+
+// NewObservedClient returns Client notifying the observer about every operation of client
+func NewObservedClient(client Client, observer RequestObserver) Client {
+	return &observedClient{
+		client:   client,
+		observer: observer,
+	}
+}
+
+func (c *observedClient) CreateRuntime(ctx context.Context, config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
+	start := time.Now()
+	id, err := c.client.CreateRuntime(ctx, config, globalAccount)
+	c.observer.ObserveDirectorRequest(ctx, OperationCreateRuntime, time.Since(start), err)
+	return id, err
+}
+
+func (c *observedClient) GetRuntime(ctx context.Context, compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
+	start := time.Now()
+	runtime, err := c.client.GetRuntime(ctx, compassID, globalAccount)
+	c.observer.ObserveDirectorRequest(ctx, OperationGetRuntime, time.Since(start), err)
+	return runtime, err
+}
+
+func (c *observedClient) ListRuntimes(ctx context.Context, labels map[string]string, globalAccount string) ([]graphql.RuntimeExt, apperrors.AppError) {
+	start := time.Now()
+	runtimes, err := c.client.ListRuntimes(ctx, labels, globalAccount)
+	c.observer.ObserveDirectorRequest(ctx, OperationListRuntimes, time.Since(start), err)
+	return runtimes, err
+}
+
+func (c *observedClient) SetRuntimeLabel(ctx context.Context, compassID, globalAccount, key, value string) apperrors.AppError {
+	start := time.Now()
+	err := c.client.SetRuntimeLabel(ctx, compassID, globalAccount, key, value)
+	c.observer.ObserveDirectorRequest(ctx, OperationSetRuntimeLabel, time.Since(start), err)
+	return err
+}
+
+func (c *observedClient) GetConnectionToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
+	start := time.Now()
+	token, err := c.client.GetConnectionToken(ctx, compassID, globalAccount)
+	c.observer.ObserveDirectorRequest(ctx, OperationGetConnectionToken, time.Since(start), err)
+	return token, err
+}
+
+func (c *observedClient) DeleteRuntime(ctx context.Context, compassID, globalAccount string) apperrors.AppError {
+	start := time.Now()
+	err := c.client.DeleteRuntime(ctx, compassID, globalAccount)
+	c.observer.ObserveDirectorRequest(ctx, OperationDeleteRuntime, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,66 @@
+package director
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/director/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	backend   string
+	operation string
+	err       apperrors.AppError
+}
+
+type observerStub struct {
+	observations []observation
+}
+
+func (o *observerStub) ObserveDirectorRequest(ctx context.Context, operation string, _ time.Duration, err apperrors.AppError) {
+	o.observations = append(o.observations, observation{backend: BackendFromContext(ctx), operation: operation, err: err})
+}
+
+func TestObservedClient(t *testing.T) {
+	t.Run("should observe successful operation", func(t *testing.T) {
+		directorClient := &mocks.Client{}
+		directorClient.On("GetRuntime", mock.Anything, "id", "ga").Return(graphql.RuntimeExt{Runtime: graphql.Runtime{ID: "id"}}, nil)
+		observer := &observerStub{}
+
+		runtime, err := NewObservedClient(directorClient, observer).GetRuntime(context.Background(), "id", "ga")
+
+		require.Nil(t, err)
+		assert.Equal(t, "id", runtime.ID)
+		assert.Equal(t, []observation{{operation: OperationGetRuntime}}, observer.observations)
+	})
+
+	t.Run("should observe failed operation with its error", func(t *testing.T) {
+		notFound := apperrors.NotFound("runtime not found").SetReason(apperrors.ErrDirectorRuntimeNotFound)
+		directorClient := &mocks.Client{}
+		directorClient.On("DeleteRuntime", mock.Anything, "id", "ga").Return(notFound)
+		observer := &observerStub{}
+
+		err := NewObservedClient(directorClient, observer).DeleteRuntime(context.Background(), "id", "ga")
+
+		assert.Equal(t, notFound, err)
+		assert.Equal(t, []observation{{operation: OperationDeleteRuntime, err: notFound}}, observer.observations)
+	})
+
+	t.Run("should observe operation with the Director backend chosen by the router", func(t *testing.T) {
+		directorClient := &mocks.Client{}
+		directorClient.On("DeleteRuntime", mock.Anything, "id", "ga").Return(nil)
+		observer := &observerStub{}
+		router, err := NewRouter("eu", map[string]Client{"eu": NewObservedClient(directorClient, observer)})
+		require.NoError(t, err)
+
+		require.Nil(t, router.DeleteRuntime(context.Background(), "id", "ga"))
+
+		assert.Equal(t, []observation{{backend: "eu", operation: OperationDeleteRuntime}}, observer.observations)
+	})
+}
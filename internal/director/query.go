@@ -1,6 +1,11 @@
 package director
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
+
+const runtimesPageSize = 200
 
 type queryProvider struct{}
 
@@ -12,10 +17,30 @@ func (qp queryProvider) createRuntimeMutation(runtimeInput string) string {
 func (qp queryProvider) getRuntimeQuery(compassID string) string {
 	return fmt.Sprintf(`query {
     result: runtime(id: "%s") {
-         id name description labels
+         id name description labels status { condition }
 }}`, compassID)
 }
 
+func (qp queryProvider) listRuntimesQuery(filters, cursor string) string {
+	after := ""
+	if cursor != "" {
+		after = fmt.Sprintf(`, after: "%s"`, cursor)
+	}
+	return fmt.Sprintf(`query {
+	result: runtimes(filter: [%s], first: %d%s) {
+		data { id name description labels }
+		pageInfo { endCursor hasNextPage }
+		totalCount
+}}`, filters, runtimesPageSize, after)
+}
+
+func (qp queryProvider) setRuntimeLabelMutation(compassID, key, value string) string {
+	return fmt.Sprintf(`mutation {
+	result: setRuntimeLabel(runtimeID: "%s", key: "%s", value: %s) {
+		key value
+}}`, compassID, key, strconv.Quote(value))
+}
+
 func (qp queryProvider) deleteRuntimeMutation(runtimeID string) string {
 	return fmt.Sprintf(`mutation {
 	result: unregisterRuntime(id: "%s") {
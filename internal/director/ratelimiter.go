@@ -0,0 +1,87 @@
+package director
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// evictionInterval is how often the limiters of idle Global Accounts are removed
+const evictionInterval = time.Minute
+
+// RateLimiter limits the rate of requests sent to Director, both in total and for each Global Account,
+// so that a single Global Account can't use up the whole request budget. The limiters of Global Accounts which are idle long enough
+// to refill their burst are removed, so that they don't pile up.
+type RateLimiter struct {
+	global *rate.Limiter
+
+	mu                 sync.Mutex
+	globalAccounts     map[string]*rate.Limiter
+	globalAccountLimit rate.Limit
+	globalAccountBurst int
+	lastEviction       time.Time
+}
+
+// NewRateLimiter creates RateLimiter allowing qps requests per second in total, and globalAccountQPS requests per second for each Global Account.
+// A rate which is not positive is not limited, and a burst which is not positive allows a single request at once.
+func NewRateLimiter(qps float64, burst int, globalAccountQPS float64, globalAccountBurst int) *RateLimiter {
+	return &RateLimiter{
+		global:             rate.NewLimiter(limit(qps), max(burst, 1)),
+		globalAccounts:     make(map[string]*rate.Limiter),
+		globalAccountLimit: limit(globalAccountQPS),
+		globalAccountBurst: max(globalAccountBurst, 1),
+		lastEviction:       time.Now(),
+	}
+}
+
+// Wait blocks until a request for the Global Account is allowed, or ctx is done. The nil RateLimiter allows all requests.
+func (l *RateLimiter) Wait(ctx context.Context, globalAccount string) error {
+	if l == nil {
+		return nil
+	}
+
+	// wait for the Global Account first, so that requests waiting for a busy Global Account don't hold the tokens of others
+	if err := l.forGlobalAccount(globalAccount).Wait(ctx); err != nil {
+		return err
+	}
+	return l.global.Wait(ctx)
+}
+
+func (l *RateLimiter) forGlobalAccount(globalAccount string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.globalAccountLimit == rate.Inf {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if now := time.Now(); now.Sub(l.lastEviction) >= evictionInterval {
+		l.evictIdle(now)
+	}
+
+	limiter, ok := l.globalAccounts[globalAccount]
+	if !ok {
+		limiter = rate.NewLimiter(l.globalAccountLimit, l.globalAccountBurst)
+		l.globalAccounts[globalAccount] = limiter
+	}
+	return limiter
+}
+
+// evictIdle removes the limiters with the full burst, as they behave the same as new ones
+func (l *RateLimiter) evictIdle(now time.Time) {
+	for globalAccount, limiter := range l.globalAccounts {
+		if limiter.TokensAt(now) >= float64(l.globalAccountBurst) {
+			delete(l.globalAccounts, globalAccount)
+		}
+	}
+	l.lastEviction = now
+}
+
+func limit(qps float64) rate.Limit {
+	if qps <= 0 || math.IsInf(qps, 1) {
+		return rate.Inf
+	}
+	return rate.Limit(qps)
+}
@@ -0,0 +1,76 @@
+package director
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("should limit requests of a single Global Account", func(t *testing.T) {
+		// given
+		limiter := NewRateLimiter(0, 0, 0.001, 1)
+		require.NoError(t, limiter.Wait(context.Background(), "ga"))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// then
+		assert.Error(t, limiter.Wait(ctx, "ga"))
+		assert.NoError(t, limiter.Wait(ctx, "other-ga"))
+	})
+
+	t.Run("should limit requests of all Global Accounts together", func(t *testing.T) {
+		// given
+		limiter := NewRateLimiter(0.001, 1, 0, 0)
+		require.NoError(t, limiter.Wait(context.Background(), "ga"))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// then
+		assert.Error(t, limiter.Wait(ctx, "other-ga"))
+	})
+
+	t.Run("should allow a single request at once when burst is not positive", func(t *testing.T) {
+		// given
+		limiter := NewRateLimiter(1000, 0, 1000, -1)
+
+		// then
+		assert.NoError(t, limiter.Wait(context.Background(), "ga"))
+		assert.NoError(t, limiter.Wait(context.Background(), "ga"))
+	})
+
+	t.Run("should remove limiters of idle Global Accounts", func(t *testing.T) {
+		// given
+		limiter := NewRateLimiter(0, 0, 1000, 1)
+		require.NoError(t, limiter.Wait(context.Background(), "idle-ga"))
+		time.Sleep(10 * time.Millisecond)
+		limiter.lastEviction = time.Time{}
+
+		// when
+		require.NoError(t, limiter.Wait(context.Background(), "ga"))
+
+		// then
+		assert.NotContains(t, limiter.globalAccounts, "idle-ga")
+		assert.Contains(t, limiter.globalAccounts, "ga")
+	})
+
+	t.Run("should not keep limiters when Global Accounts are not limited", func(t *testing.T) {
+		// given
+		limiter := NewRateLimiter(0, 0, 0, 0)
+
+		// when
+		require.NoError(t, limiter.Wait(context.Background(), "ga"))
+
+		// then
+		assert.Empty(t, limiter.globalAccounts)
+	})
+
+	t.Run("should allow all requests when limiter is not set", func(t *testing.T) {
+		var limiter *RateLimiter
+
+		assert.NoError(t, limiter.Wait(context.Background(), "ga"))
+	})
+}
@@ -0,0 +1,126 @@
+package director
+
+import (
+	"context"
+	"slices"
+
+	"github.com/kyma-incubator/compass/components/director/pkg/graphql"
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/pkg/gqlschema"
+	"github.com/pkg/errors"
+)
+
+type backendContextKey struct{}
+
+// ContextWithBackend returns the context routing the requests of the Router to the named Director backend
+func ContextWithBackend(ctx context.Context, backend string) context.Context {
+	return context.WithValue(ctx, backendContextKey{}, backend)
+}
+
+// BackendFromContext returns the name of the Director backend set with ContextWithBackend, or an empty name if it's not set
+func BackendFromContext(ctx context.Context) string {
+	backend, _ := ctx.Value(backendContextKey{}).(string)
+	return backend
+}
+
+// Router is Client sending each request to the Director backend set in its context with ContextWithBackend.
+// Requests without the backend in the context are sent to the default backend.
+type Router struct {
+	backends       map[string]Client
+	defaultBackend string
+}
+
+// NewRouter creates Router for the named Director backends. The default backend must be one of them.
+func NewRouter(defaultBackend string, backends map[string]Client) (*Router, error) {
+	if _, ok := backends[defaultBackend]; !ok {
+		return nil, errors.Errorf("default Director backend %q is not configured", defaultBackend)
+	}
+	return &Router{
+		backends:       backends,
+		defaultBackend: defaultBackend,
+	}, nil
+}
+
+// DefaultBackend returns the name of the backend used when the context doesn't set one
+func (r *Router) DefaultBackend() string {
+	return r.defaultBackend
+}
+
+// Backends returns the sorted names of all backends
+func (r *Router) Backends() []string {
+	names := make([]string, 0, len(r.backends))
+	for name := range r.backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HasBackend returns true if the backend with the given name is configured
+func (r *Router) HasBackend(name string) bool {
+	_, ok := r.backends[name]
+	return ok
+}
+
+// client returns the client of the backend set in ctx, and the context with the name of the backend, also when the default one is chosen,
+// so that the client can tell which backend it's called for, e.g. in the metrics
+func (r *Router) client(ctx context.Context) (context.Context, Client, apperrors.AppError) {
+	name := BackendFromContext(ctx)
+	if name == "" {
+		name = r.defaultBackend
+	}
+
+	client, ok := r.backends[name]
+	if !ok {
+		return ctx, nil, apperrors.BadRequest("Unknown Director backend " + name).SetComponent(apperrors.ErrCompassDirectorClient).SetReason(apperrors.ErrDirectorUnknownBackend)
+	}
+	return ContextWithBackend(ctx, name), client, nil
+}
+
+func (r *Router) CreateRuntime(ctx context.Context, config *gqlschema.RuntimeInput, globalAccount string) (string, apperrors.AppError) {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return "", err
+	}
+	return client.CreateRuntime(ctx, config, globalAccount)
+}
+
+func (r *Router) GetRuntime(ctx context.Context, compassID, globalAccount string) (graphql.RuntimeExt, apperrors.AppError) {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return graphql.RuntimeExt{}, err
+	}
+	return client.GetRuntime(ctx, compassID, globalAccount)
+}
+
+func (r *Router) ListRuntimes(ctx context.Context, labels map[string]string, globalAccount string) ([]graphql.RuntimeExt, apperrors.AppError) {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListRuntimes(ctx, labels, globalAccount)
+}
+
+func (r *Router) SetRuntimeLabel(ctx context.Context, compassID, globalAccount, key, value string) apperrors.AppError {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.SetRuntimeLabel(ctx, compassID, globalAccount, key, value)
+}
+
+func (r *Router) GetConnectionToken(ctx context.Context, compassID, globalAccount string) (graphql.OneTimeTokenForRuntimeExt, apperrors.AppError) {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return graphql.OneTimeTokenForRuntimeExt{}, err
+	}
+	return client.GetConnectionToken(ctx, compassID, globalAccount)
+}
+
+func (r *Router) DeleteRuntime(ctx context.Context, compassID, globalAccount string) apperrors.AppError {
+	ctx, client, err := r.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.DeleteRuntime(ctx, compassID, globalAccount)
+}
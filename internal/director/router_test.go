@@ -0,0 +1,64 @@
+package director
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backendStub records the name of the backend receiving DeleteRuntime, other methods are not implemented
+type backendStub struct {
+	Client
+	name  string
+	calls *[]string
+}
+
+func (b backendStub) DeleteRuntime(_ context.Context, _, _ string) apperrors.AppError {
+	*b.calls = append(*b.calls, b.name)
+	return nil
+}
+
+func TestRouter(t *testing.T) {
+	var calls []string
+	router, err := NewRouter("eu", map[string]Client{
+		"eu": backendStub{name: "eu", calls: &calls},
+		"us": backendStub{name: "us", calls: &calls},
+	})
+	require.NoError(t, err)
+
+	t.Run("should route request to the backend from the context", func(t *testing.T) {
+		calls = nil
+
+		require.NoError(t, router.DeleteRuntime(ContextWithBackend(context.Background(), "us"), "id", "ga"))
+
+		assert.Equal(t, []string{"us"}, calls)
+	})
+
+	t.Run("should route request without backend in the context to the default backend", func(t *testing.T) {
+		calls = nil
+
+		require.NoError(t, router.DeleteRuntime(context.Background(), "id", "ga"))
+
+		assert.Equal(t, []string{"eu"}, calls)
+	})
+
+	t.Run("should fail permanently for unknown backend", func(t *testing.T) {
+		calls = nil
+
+		err := router.DeleteRuntime(ContextWithBackend(context.Background(), "asia"), "id", "ga")
+
+		require.Error(t, err)
+		assert.Equal(t, apperrors.ErrDirectorUnknownBackend, err.Reason())
+		assert.False(t, apperrors.IsRetryable(err))
+		assert.Empty(t, calls)
+	})
+
+	t.Run("should require the default backend to be configured", func(t *testing.T) {
+		_, err := NewRouter("asia", map[string]Client{"eu": backendStub{}})
+
+		assert.Error(t, err)
+	})
+}
@@ -8,25 +8,26 @@ import (
 	"time"
 
 	directorApperrors "github.com/kyma-incubator/compass/components/director/pkg/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
 	"github.com/kyma-project/compass-manager/third_party/machinebox/graphql"
-	"github.com/sirupsen/logrus"
 )
 
 const (
-	timeout = 30 * time.Second
+	// defaultTimeout limits requests made with a context without a deadline
+	defaultTimeout = 30 * time.Second
 )
 
 type ClientConstructor func(certificate *tls.Certificate, graphqlEndpoint string, enableLogging bool, insecureConfigFetch bool) (Client, error)
 
 //go:generate mockery --name=Client
 type Client interface {
-	Do(req *graphql.Request, res interface{}, gracefulUnregistration bool) error
+	Do(ctx context.Context, req *graphql.Request, res interface{}, gracefulUnregistration bool) error
 }
 
 type client struct {
-	gqlClient *graphql.Client
-	logs      []string
-	logging   bool
+	endpoint   string
+	httpClient *http.Client
+	logging    bool
 }
 
 func NewGraphQLClient(graphqlEndpoint string, enableLogging bool, insecureSkipVerify bool) Client {
@@ -36,25 +37,35 @@ func NewGraphQLClient(graphqlEndpoint string, enableLogging bool, insecureSkipVe
 		},
 	}
 
-	gqlClient := graphql.NewClient(graphqlEndpoint, graphql.WithHTTPClient(httpClient))
+	return NewGraphQLClientWithHTTPClient(graphqlEndpoint, enableLogging, httpClient)
+}
 
-	client := &client{
-		gqlClient: gqlClient,
-		logging:   enableLogging,
-		logs:      []string{},
+// NewGraphQLClientWithHTTPClient creates the client sending requests with httpClient, e.g. one authenticating with the client certificate
+func NewGraphQLClientWithHTTPClient(graphqlEndpoint string, enableLogging bool, httpClient *http.Client) Client {
+	return &client{
+		endpoint:   graphqlEndpoint,
+		httpClient: httpClient,
+		logging:    enableLogging,
 	}
-
-	client.gqlClient.Log = client.addLog
-
-	return client
 }
 
-func (c *client) Do(req *graphql.Request, res interface{}, gracefulUnregistration bool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+func (c *client) Do(ctx context.Context, req *graphql.Request, res interface{}, gracefulUnregistration bool) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+
+	// the logs are collected per request, as the client is shared by the concurrent reconciliations
+	var logs []string
+	gqlClient := graphql.NewClient(c.endpoint, graphql.WithHTTPClient(c.httpClient))
+	if c.logging {
+		gqlClient.Log = func(log string) {
+			logs = append(logs, log)
+		}
+	}
 
-	c.clearLogs()
-	err := c.gqlClient.Run(ctx, req, res)
+	err := gqlClient.Run(ctx, req, res)
 	if err == nil {
 		return nil
 	}
@@ -66,33 +77,22 @@ func (c *client) Do(req *graphql.Request, res interface{}, gracefulUnregistratio
 			errs := errors.New("failed to read the error code from the error response. Original error: ")
 			return errors.Join(errs, egErr)
 		}
-		errorCode, ok := errorCodeValue.(int64)
+		// the error code is decoded from the JSON response as float64
+		errorCode, ok := errorCodeValue.(float64)
 		if !ok {
 			errs := errors.New("failed to cast the error code from the error response. Original error: ")
 			return errors.Join(errs, egErr)
 		}
 
-		if directorApperrors.ErrorType(errorCode) == directorApperrors.NotFound {
+		if directorApperrors.ErrorType(int(errorCode)) == directorApperrors.NotFound {
 			return err
 		}
 	}
-	for _, l := range c.logs {
+	for _, l := range logs {
 		if l != "" {
-			logrus.Info(l)
+			logging.FromContext(ctx).Debug(logging.Redact(l))
 		}
 	}
 
 	return err
 }
-
-func (c *client) addLog(log string) {
-	if !c.logging {
-		return
-	}
-
-	c.logs = append(c.logs, log)
-}
-
-func (c *client) clearLogs() {
-	c.logs = []string{}
-}
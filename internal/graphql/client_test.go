@@ -0,0 +1,104 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	directorApperrors "github.com/kyma-incubator/compass/components/director/pkg/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/kyma-project/compass-manager/third_party/machinebox/graphql"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_LogsWithoutSecrets(t *testing.T) {
+	const (
+		accessToken  = "director-access-token"
+		oneTimeToken = "one-time-token-for-runtime"
+	)
+
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"result":{"token":"` + oneTimeToken + `","connectorURL":"https://connector/graphql?token=` + oneTimeToken + `"}},` +
+			`"errors":[{"message":"Internal Server Error"}]}`))
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	require.NoError(t, logging.Configure(logger, logging.Config{Level: "debug"}))
+	output := &bytes.Buffer{}
+	logger.SetOutput(output)
+	ctx := logging.IntoContext(context.Background(), logrus.NewEntry(logger))
+
+	request := graphql.NewRequest(`mutation { result: requestOneTimeTokenForRuntime(id: "runtime-id") { token connectorURL } }`)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	client := NewGraphQLClientWithHTTPClient(server.URL, true, server.Client())
+
+	// when
+	err := client.Do(ctx, request, &struct{}{}, false)
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, output.String(), "requestOneTimeTokenForRuntime", "request and response should be logged")
+	assert.Contains(t, output.String(), logging.Redacted)
+	assert.NotContains(t, output.String(), accessToken)
+	assert.NotContains(t, output.String(), oneTimeToken)
+}
+
+func TestClient_GracefulUnregistration(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"errors":[{"message":"Object not found","extensions":{"error_code":%d}}]}`, directorApperrors.NotFound)))
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	require.NoError(t, logging.Configure(logger, logging.Config{Level: "debug"}))
+	output := &bytes.Buffer{}
+	logger.SetOutput(output)
+	ctx := logging.IntoContext(context.Background(), logrus.NewEntry(logger))
+
+	client := NewGraphQLClientWithHTTPClient(server.URL, true, server.Client())
+
+	// when
+	err := client.Do(ctx, graphql.NewRequest(`mutation { result: unregisterRuntime(id: "runtime-id") { id } }`), &struct{}{}, true)
+
+	// then
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "failed to cast the error code")
+	assert.Empty(t, output.String(), "not found error should not be logged on graceful unregistration")
+}
+
+func TestClient_ConcurrentRequests(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Internal Server Error"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClientWithHTTPClient(server.URL, true, server.Client())
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.Do(context.Background(), graphql.NewRequest(`query { result: runtimes { data { id } } }`), &struct{}{}, false)
+			assert.Error(t, err)
+		}()
+	}
+
+	// then
+	wg.Wait()
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	graphql "github.com/kyma-project/compass-manager/third_party/machinebox/graphql"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,13 +14,13 @@ type Client struct {
 	mock.Mock
 }
 
-// Do provides a mock function with given fields: req, res, gracefulUnregistration
-func (_m *Client) Do(req *graphql.Request, res interface{}, gracefulUnregistration bool) error {
-	ret := _m.Called(req, res, gracefulUnregistration)
+// Do provides a mock function with given fields: ctx, req, res, gracefulUnregistration
+func (_m *Client) Do(ctx context.Context, req *graphql.Request, res interface{}, gracefulUnregistration bool) error {
+	ret := _m.Called(ctx, req, res, gracefulUnregistration)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*graphql.Request, interface{}, bool) error); ok {
-		r0 = rf(req, res, gracefulUnregistration)
+	if rf, ok := ret.Get(0).(func(context.Context, *graphql.Request, interface{}, bool) error); ok {
+		r0 = rf(ctx, req, res, gracefulUnregistration)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelPath is the path of the LevelHandler on the log level server, which listens on a loopback address only
+const LevelPath = "/loglevel"
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler serves the level of the logger on GET, and changes it on PUT with the {"level": "debug"} body,
+// so that the level can be adjusted without restarting Compass Manager
+func LevelHandler(logger *logrus.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			body := levelBody{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			level, err := logrus.ParseLevel(body.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if level != logger.GetLevel() {
+				logger.Infof("Changing log level from %s to %s", logger.GetLevel(), level)
+				logger.SetLevel(level)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelBody{Level: logger.GetLevel().String()})
+	})
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FieldKyma is the name of the Kyma resource the log entry is about
+	FieldKyma = "kyma"
+	// FieldGlobalAccount is the Global Account, used as the tenant in Compass, of the Runtime
+	FieldGlobalAccount = "globalAccount"
+	// FieldCompassRuntimeID is the ID of the Runtime in Compass
+	FieldCompassRuntimeID = "compassRuntimeID"
+	// FieldReconcileID identifies the reconciliation which logged the entry
+	FieldReconcileID = "reconcileID"
+
+	// FormatJSON writes every entry as a JSON object
+	FormatJSON = "json"
+	// FormatText writes the entries as key=value pairs, for reading them on the terminal
+	FormatText = "text"
+)
+
+// Config is the initial level and the output format of the logger
+type Config struct {
+	Level  string
+	Format string
+}
+
+// Setup configures the standard logrus logger, and returns it to be injected into the components. Packages which aren't given the logger
+// explicitly log with it as well, through FromContext, so that all the logs of Compass Manager have the same format and level.
+func Setup(config Config) (*logrus.Logger, error) {
+	logger := logrus.StandardLogger()
+	if err := Configure(logger, config); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+// Configure sets the level and the output format of the logger. The secrets are masked with Redact in all entries.
+func Configure(logger *logrus.Logger, config Config) error {
+	level, err := logrus.ParseLevel(config.Level)
+	if err != nil {
+		return errors.Wrap(err, "invalid log level")
+	}
+
+	switch config.Format {
+	case FormatJSON, "":
+		logger.SetFormatter(redactingFormatter{formatter: &logrus.JSONFormatter{}})
+	case FormatText:
+		logger.SetFormatter(redactingFormatter{formatter: &logrus.TextFormatter{FullTimestamp: true}})
+	default:
+		return errors.Errorf("unknown log format %q, expected %q or %q", config.Format, FormatJSON, FormatText)
+	}
+	logger.SetLevel(level)
+	return nil
+}
+
+type contextKey struct{}
+
+// IntoContext returns the context carrying the logger, which is returned by FromContext in the functions called with the context
+func IntoContext(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx with the fields of the processed Kyma resource, or the standard logger if ctx has none
+func FromContext(ctx context.Context) *logrus.Entry {
+	return FromContextOr(ctx, logrus.StandardLogger())
+}
+
+// FromContextOr returns the logger stored in ctx with the fields of the processed Kyma resource, or the injected logger if ctx has none
+func FromContextOr(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(logger)
+}
+
+// WithFields returns the context carrying the logger of ctx extended with the fields
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return IntoContext(ctx, FromContext(ctx).WithFields(fields))
+}
@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Run("should write JSON entries with the fields", func(t *testing.T) {
+		logger, output := newLogger(t, Config{Level: "debug", Format: FormatJSON})
+
+		logger.WithField(FieldKyma, "kyma-1").Debug("message")
+
+		entry := decodeEntry(t, output)
+		assert.Equal(t, "message", entry["msg"])
+		assert.Equal(t, "debug", entry["level"])
+		assert.Equal(t, "kyma-1", entry[FieldKyma])
+	})
+
+	t.Run("should skip entries below the level", func(t *testing.T) {
+		logger, output := newLogger(t, Config{Level: "warn"})
+
+		logger.Info("message")
+
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("should fail for invalid level", func(t *testing.T) {
+		require.Error(t, Configure(logrus.New(), Config{Level: "verbose"}))
+	})
+
+	t.Run("should fail for unknown format", func(t *testing.T) {
+		require.Error(t, Configure(logrus.New(), Config{Level: "info", Format: "xml"}))
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	logger, output := newLogger(t, Config{Level: "info"})
+
+	t.Run("should return the injected logger if context has none", func(t *testing.T) {
+		output.Reset()
+
+		FromContextOr(context.Background(), logger).Info("message")
+
+		assert.Equal(t, "message", decodeEntry(t, output)["msg"])
+	})
+
+	t.Run("should return the logger with the fields added to the context", func(t *testing.T) {
+		output.Reset()
+		ctx := IntoContext(context.Background(), logger.WithField(FieldKyma, "kyma-1"))
+		ctx = WithFields(ctx, logrus.Fields{FieldGlobalAccount: "ga", FieldCompassRuntimeID: "runtime-id"})
+
+		FromContext(ctx).Info("message")
+
+		entry := decodeEntry(t, output)
+		assert.Equal(t, "kyma-1", entry[FieldKyma])
+		assert.Equal(t, "ga", entry[FieldGlobalAccount])
+		assert.Equal(t, "runtime-id", entry[FieldCompassRuntimeID])
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	logger, _ := newLogger(t, Config{Level: "info"})
+	handler := LevelHandler(logger)
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, LevelPath, strings.NewReader(body)))
+		return recorder
+	}
+
+	t.Run("should return the level", func(t *testing.T) {
+		response := serve(http.MethodGet, "")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"level": "info"}`, response.Body.String())
+	})
+
+	t.Run("should change the level", func(t *testing.T) {
+		response := serve(http.MethodPut, `{"level": "debug"}`)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"level": "debug"}`, response.Body.String())
+		assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+	})
+
+	t.Run("should reject invalid level", func(t *testing.T) {
+		response := serve(http.MethodPut, `{"level": "verbose"}`)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+	})
+
+	t.Run("should reject other methods", func(t *testing.T) {
+		response := serve(http.MethodPost, `{"level": "info"}`)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+	})
+}
+
+func TestNewLogr(t *testing.T) {
+	logger, output := newLogger(t, Config{Level: "debug"})
+	log := NewLogr(logger).WithName("controller").WithName("kyma").WithValues("namespace", "kcp-system")
+
+	t.Run("should log with the name and the values", func(t *testing.T) {
+		output.Reset()
+
+		log.Info("message", "reconcileID", "id")
+
+		entry := decodeEntry(t, output)
+		assert.Equal(t, "info", entry["level"])
+		assert.Equal(t, "controller.kyma", entry[fieldLogger])
+		assert.Equal(t, "kcp-system", entry["namespace"])
+		assert.Equal(t, "id", entry["reconcileID"])
+	})
+
+	t.Run("should map verbosity to the level", func(t *testing.T) {
+		output.Reset()
+
+		log.V(1).Info("debug message")
+		assert.Equal(t, "debug", decodeEntry(t, output)["level"])
+
+		output.Reset()
+		log.V(2).Info("trace message")
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("should log the error", func(t *testing.T) {
+		output.Reset()
+
+		log.Error(errors.New("boom"), "failed")
+
+		entry := decodeEntry(t, output)
+		assert.Equal(t, "error", entry["level"])
+		assert.Equal(t, "boom", entry[logrus.ErrorKey])
+	})
+}
+
+func newLogger(t *testing.T, config Config) (*logrus.Logger, *bytes.Buffer) {
+	logger := logrus.New()
+	require.NoError(t, Configure(logger, config))
+	output := &bytes.Buffer{}
+	logger.SetOutput(output)
+	return logger, output
+}
+
+func decodeEntry(t *testing.T, output *bytes.Buffer) map[string]interface{} {
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &entry))
+	return entry
+}
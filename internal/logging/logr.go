@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldLogger is the name of the logr logger, e.g. the controller-runtime component, which logged the entry
+const fieldLogger = "logger"
+
+// NewLogr returns logr.Logger writing to the logrus logger, so that controller-runtime logs with the same format and level as Compass Manager.
+// The logr verbosity 0 is logged on the info level, 1 on the debug level, and higher ones on the trace level.
+func NewLogr(logger *logrus.Logger) logr.Logger {
+	return logr.New(&logrSink{entry: logrus.NewEntry(logger)})
+}
+
+type logrSink struct {
+	entry *logrus.Entry
+	name  string
+}
+
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+func (s *logrSink) Enabled(level int) bool {
+	return s.entry.Logger.IsLevelEnabled(logrusLevel(level))
+}
+
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.entry.WithFields(fields(keysAndValues)).Log(logrusLevel(level), msg)
+}
+
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.entry.WithFields(fields(keysAndValues)).WithError(err).Error(msg)
+}
+
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logrSink{entry: s.entry.WithFields(fields(keysAndValues)), name: s.name}
+}
+
+func (s *logrSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return &logrSink{entry: s.entry.WithField(fieldLogger, name), name: name}
+}
+
+func logrusLevel(level int) logrus.Level {
+	switch {
+	case level <= 0:
+		return logrus.InfoLevel
+	case level == 1:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
+
+// fields converts the logr key-value pairs to logrus fields. The value of a key without one is logged as null.
+func fields(keysAndValues []interface{}) logrus.Fields {
+	result := make(logrus.Fields, (len(keysAndValues)+1)/2) //nolint:mnd
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			result[key] = keysAndValues[i+1]
+		} else {
+			result[key] = nil
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the secrets masked by Redact
+const Redacted = "***"
+
+var (
+	// secretFieldPattern matches the token, secret, password and assertion fields with their values in JSON, GraphQL, form-encoded
+	// and printed Go maps, also when the JSON is escaped in a string. Unquoted values must follow the separator directly,
+	// so that messages like "failed to get token: reason" are kept.
+	secretFieldPattern = regexp.MustCompile(`(?i)(\b\w*(?:token|secret|password|assertion|rawEncoded)\\*"?[:=])(\s*\\*"(?:[^"\\]|\\.)*?\\*"|[^\s&,;}\])"'\\]+)`) //nolint:gochecknoglobals
+	// bearerPattern matches the credentials of the Authorization header
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*|\b(basic\s+)[A-Za-z0-9+/]{8,}=*`) //nolint:gochecknoglobals
+	// jwtPattern matches JSON Web Tokens, e.g. access tokens and client assertions logged without their field name
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`) //nolint:gochecknoglobals
+	// privateKeyPattern matches PEM encoded private keys
+	privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`) //nolint:gochecknoglobals
+)
+
+// Redact masks the secrets in s: the values of the token, client secret, password and client assertion fields, among others the OAuth
+// access token and the one-time token of the Connector, the credentials of the Authorization header, JSON Web Tokens and private keys
+func Redact(s string) string {
+	s = privateKeyPattern.ReplaceAllString(s, Redacted)
+	s = secretFieldPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := secretFieldPattern.FindStringSubmatch(match)
+		field, value := groups[1], groups[2]
+		opening := value[:len(value)-len(strings.TrimLeft(value, " \t\r\n\\\""))]
+		closing := strings.TrimLeft(opening, " \t\r\n")
+		return field + opening + Redacted + closing
+	})
+	s = bearerPattern.ReplaceAllString(s, "${1}${2}"+Redacted)
+	return jwtPattern.ReplaceAllString(s, Redacted)
+}
+
+// redactingFormatter masks the secrets in the message and the fields of the entries before they are formatted,
+// so that no secret reaches the log output, whichever component logged it
+type redactingFormatter struct {
+	formatter logrus.Formatter
+}
+
+func (f redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	redacted := *entry
+	redacted.Message = Redact(entry.Message)
+	redacted.Data = make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		redacted.Data[key] = redactValue(value)
+	}
+	return f.formatter.Format(&redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool, int, int32, int64, uint, uint32, uint64, float32, float64, time.Duration, time.Time, nil:
+		return value
+	case string:
+		return Redact(v)
+	case error:
+		return Redact(v.Error())
+	case fmt.Stringer:
+		return Redact(v.String())
+	case []byte:
+		return Redact(string(v))
+	default:
+		return redactStructured(value)
+	}
+}
+
+// redactStructured masks the secrets in the JSON of the value, e.g. the map of the request headers, keeping it structured if it's possible
+func redactStructured(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Redact(fmt.Sprintf("%+v", value))
+	}
+	redacted := Redact(string(data))
+	if redacted == string(data) {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal([]byte(redacted), &result); err != nil {
+		return redacted
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package mtls
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	CertificateKey = corev1.TLSCertKey
+	PrivateKeyKey  = corev1.TLSPrivateKeyKey
+	CABundleKey    = "ca.crt"
+)
+
+// Bundle is the PEM encoded client certificate with its private key, and the optional CA bundle used to verify the server
+type Bundle struct {
+	Certificate []byte
+	PrivateKey  []byte
+	CABundle    []byte
+}
+
+// Source loads the current Bundle, e.g. after the certificate was rotated
+type Source interface {
+	Load(ctx context.Context) (Bundle, error)
+}
+
+type fileSource struct {
+	certificatePath string
+	privateKeyPath  string
+	caBundlePath    string
+}
+
+// NewFileSource creates Source reading the Bundle from files. The CA bundle is not loaded when caBundlePath is empty.
+func NewFileSource(certificatePath, privateKeyPath, caBundlePath string) Source {
+	return &fileSource{
+		certificatePath: certificatePath,
+		privateKeyPath:  privateKeyPath,
+		caBundlePath:    caBundlePath,
+	}
+}
+
+func (s *fileSource) Load(_ context.Context) (Bundle, error) {
+	var bundle Bundle
+	var err error
+
+	if bundle.Certificate, err = os.ReadFile(s.certificatePath); err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to read client certificate")
+	}
+	if bundle.PrivateKey, err = os.ReadFile(s.privateKeyPath); err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to read client private key")
+	}
+	if s.caBundlePath != "" {
+		if bundle.CABundle, err = os.ReadFile(s.caBundlePath); err != nil {
+			return Bundle{}, errors.Wrap(err, "failed to read CA bundle")
+		}
+	}
+	return bundle, nil
+}
+
+type secretSource struct {
+	reader client.Reader
+	name   types.NamespacedName
+}
+
+// NewSecretSource creates Source reading the Bundle from the `tls.crt`, `tls.key` and optional `ca.crt` keys of the secret
+func NewSecretSource(reader client.Reader, name types.NamespacedName) Source {
+	return &secretSource{
+		reader: reader,
+		name:   name,
+	}
+}
+
+func (s *secretSource) Load(ctx context.Context) (Bundle, error) {
+	secret := corev1.Secret{}
+	if err := s.reader.Get(ctx, s.name, &secret); err != nil {
+		return Bundle{}, errors.Wrapf(err, "failed to get secret %s with client certificate", s.name)
+	}
+
+	bundle := Bundle{
+		Certificate: secret.Data[CertificateKey],
+		PrivateKey:  secret.Data[PrivateKeyKey],
+		CABundle:    secret.Data[CABundleKey],
+	}
+	if len(bundle.Certificate) == 0 || len(bundle.PrivateKey) == 0 {
+		return Bundle{}, errors.Errorf("secret %s doesn't contain %s and %s keys", s.name, CertificateKey, PrivateKeyKey)
+	}
+	return bundle, nil
+}
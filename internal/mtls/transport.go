@@ -0,0 +1,115 @@
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Transport is http.RoundTripper authenticating requests with the client certificate loaded from the Source.
+// The certificate is loaded again periodically, and connections made after it was rotated use the new certificate.
+type Transport struct {
+	Log          *log.Logger
+	source       Source
+	reloadPeriod time.Duration
+
+	current atomic.Pointer[loadedTransport]
+}
+
+type loadedTransport struct {
+	bundle    Bundle
+	transport *http.Transport
+}
+
+func NewTransport(source Source, reloadPeriod time.Duration, log *log.Logger) *Transport {
+	return &Transport{
+		Log:          log,
+		source:       source,
+		reloadPeriod: reloadPeriod,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	current := t.current.Load()
+	if current == nil {
+		return nil, errors.New("client certificate for Director is not loaded")
+	}
+	return current.transport.RoundTrip(req)
+}
+
+// Start reloads the certificate periodically until the context is cancelled. It implements manager.Runnable.
+func (t *Transport) Start(ctx context.Context) error {
+	t.Log.Infof("Starting reload of the client certificate for Director every %s", t.reloadPeriod)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := t.Reload(ctx); err != nil {
+			t.Log.Warnf("Failed to reload client certificate for Director, using the previous one: %v", err)
+		}
+	}, t.reloadPeriod)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the certificate is reloaded in every replica
+func (t *Transport) NeedLeaderElection() bool {
+	return false
+}
+
+// Reload loads the Bundle from the Source, and replaces the transport used for new requests if the Bundle changed
+func (t *Transport) Reload(ctx context.Context) error {
+	bundle, err := t.source.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := t.current.Load()
+	if previous != nil && previous.bundle.equal(bundle) {
+		return nil
+	}
+
+	tlsConfig, err := bundle.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.TLSClientConfig = tlsConfig
+	t.current.Store(&loadedTransport{bundle: bundle, transport: transport})
+
+	if previous != nil {
+		previous.transport.CloseIdleConnections()
+		t.Log.Infof("Client certificate for Director reloaded")
+	}
+	return nil
+}
+
+func (b Bundle) tlsConfig() (*tls.Config, error) {
+	certificate, err := tls.X509KeyPair(b.Certificate, b.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse client certificate")
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(b.CABundle) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(b.CABundle) {
+			return nil, errors.New("failed to parse CA bundle")
+		}
+	}
+	return config, nil
+}
+
+func (b Bundle) equal(other Bundle) bool {
+	return bytes.Equal(b.Certificate, other.Certificate) &&
+		bytes.Equal(b.PrivateKey, other.PrivateKey) &&
+		bytes.Equal(b.CABundle, other.CABundle)
+}
@@ -0,0 +1,103 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	var clientCommonName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCommonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	dir := t.TempDir()
+	certPath, keyPath, caPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caPath, caBundle, 0o600))
+	writeClientCertificate(t, certPath, keyPath, "first")
+
+	transport := NewTransport(NewFileSource(certPath, keyPath, caPath), time.Minute, logrus.New())
+	client := &http.Client{Transport: transport}
+
+	t.Run("should fail before certificate is loaded", func(t *testing.T) {
+		_, err := client.Get(server.URL) //nolint:noctx
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should authenticate with client certificate verifying server with CA bundle", func(t *testing.T) {
+		require.NoError(t, transport.Reload(context.Background()))
+
+		response, err := client.Get(server.URL) //nolint:noctx
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, "first", clientCommonName)
+	})
+
+	t.Run("should use rotated certificate after reload", func(t *testing.T) {
+		writeClientCertificate(t, certPath, keyPath, "rotated")
+		require.NoError(t, transport.Reload(context.Background()))
+
+		response, err := client.Get(server.URL) //nolint:noctx
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, "rotated", clientCommonName)
+	})
+
+	t.Run("should keep previous certificate when the new one is invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyPath, []byte("invalid"), 0o600))
+
+		assert.Error(t, transport.Reload(context.Background()))
+
+		response, err := client.Get(server.URL) //nolint:noctx
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, "rotated", clientCommonName)
+	})
+}
+
+func writeClientCertificate(t *testing.T, certPath, keyPath, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	privateKey, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKey}), 0o600))
+}
@@ -0,0 +1,130 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const clientAssertionLifetime = 5 * time.Minute
+
+// ParsePrivateKey parses the PEM encoded RSA or ECDSA P-256 private key used to sign the client assertion with private_key_jwt
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM private key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curve is supported for ECDSA private keys")
+		}
+		return k, nil
+	default:
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// clientAssertion creates the JWT authenticating the client with private_key_jwt, as defined in RFC 7523
+func clientAssertion(key crypto.Signer, keyID string, credentials credentials, now time.Time) (string, error) {
+	alg, err := signingAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	claims := map[string]interface{}{
+		"iss": credentials.clientID,
+		"sub": credentials.clientID,
+		"aud": credentials.tokensEndpoint,
+		"jti": uuid.NewString(),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	signature, err := sign(key, []byte(signingInput))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign client assertion")
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func signingAlgorithm(key crypto.Signer) (string, error) {
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PublicKey:
+		return "ES256", nil
+	default:
+		return "", errors.Errorf("unsupported private key type %T", key)
+	}
+}
+
+func encodeSegment(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal client assertion")
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func sign(key crypto.Signer, data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+
+	// JWS uses the fixed size concatenation of R and S instead of the ASN.1 encoding
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	size := (ecKey.Curve.Params().BitSize + 7) / 8 //nolint:mnd
+	signature := make([]byte, 2*size)              //nolint:mnd
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
@@ -1,81 +1,154 @@
 package oauth
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	"github.com/kyma-project/compass-manager/internal/util"
-	log "github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 )
 
 //go:generate mockery --name=Client
 type Client interface {
-	GetAuthorizationToken() (Token, apperrors.AppError)
+	GetAuthorizationToken(ctx context.Context) (Token, apperrors.AppError)
+	// UpdateCredentials replaces the credentials used by the next token requests, unless they are incomplete for the AuthStyle. It's safe for concurrent use.
+	UpdateCredentials(clientID, clientSecret, tokensEndpoint string) error
 }
 
 type oauthClient struct {
 	httpClient *http.Client
-	creds      credentials
+	options    Options
+	creds      atomic.Pointer[credentials]
+	// loaded is closed once the client has the credentials, token requests wait for it
+	loaded     chan struct{}
+	loadedOnce sync.Once
 }
 
+// NewOauthClient creates Client requesting the token with client_secret_basic and DefaultScopes
 func NewOauthClient(client *http.Client, clientID, clientSecret, tokensEndpoint string) Client {
-	return &oauthClient{
+	return NewOauthClientWithOptions(client, Options{}, clientID, clientSecret, tokensEndpoint)
+}
+
+// NewOauthClientWithOptions creates Client requesting the token as configured by the Options. Empty Scopes and AuthStyle are defaulted
+// to DefaultScopes and AuthStyleClientSecretBasic. With AuthStyleTLSClientAuth the client must present the client certificate,
+// so that the issued token is bound to it, as defined in RFC 8705. Without the client ID and the tokens endpoint the token requests wait
+// until the credentials are set with UpdateCredentials, e.g. by CredentialsWatcher.
+func NewOauthClientWithOptions(client *http.Client, options Options, clientID, clientSecret, tokensEndpoint string) Client {
+	if len(options.Scopes) == 0 {
+		options.Scopes = DefaultScopes
+	}
+	if options.AuthStyle == "" {
+		options.AuthStyle = AuthStyleClientSecretBasic
+	}
+
+	c := &oauthClient{
 		httpClient: client,
-		creds: credentials{
-			clientID:       clientID,
-			clientSecret:   clientSecret,
-			tokensEndpoint: tokensEndpoint,
-		},
+		options:    options,
+		loaded:     make(chan struct{}),
+	}
+	c.creds.Store(&credentials{
+		clientID:       clientID,
+		clientSecret:   clientSecret,
+		tokensEndpoint: tokensEndpoint,
+	})
+	if clientID != "" && tokensEndpoint != "" {
+		c.markLoaded()
+	}
+	return c
+}
+
+func (c *oauthClient) GetAuthorizationToken(ctx context.Context) (Token, apperrors.AppError) {
+	ctx, span := tracing.Start(ctx, "OAuth GetAuthorizationToken", tracing.AttributeOAuthAuthStyle.String(string(c.options.AuthStyle)))
+
+	select {
+	case <-c.loaded:
+	case <-ctx.Done():
+		appErr := apperrors.Internalf("Director OAuth credentials not loaded: %s", ctx.Err().Error()).SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
+		tracing.End(span, appErr)
+		return Token{}, appErr
+	}
+
+	token, appErr := c.getAuthorizationToken(ctx, *c.creds.Load())
+	if appErr != nil {
+		tracing.End(span, appErr)
+		return token, appErr
 	}
+	span.End()
+	return token, nil
 }
 
-func (c *oauthClient) GetAuthorizationToken() (Token, apperrors.AppError) {
-	return c.getAuthorizationToken(c.creds)
+func (c *oauthClient) UpdateCredentials(clientID, clientSecret, tokensEndpoint string) error {
+	if clientID == "" || tokensEndpoint == "" {
+		return errors.New("client_id and tokens_endpoint must not be empty")
+	}
+	if clientSecret == "" && (c.options.AuthStyle == AuthStyleClientSecretBasic || c.options.AuthStyle == AuthStyleClientSecretPost) {
+		return errors.Errorf("client_secret must not be empty with %s", c.options.AuthStyle)
+	}
+
+	c.creds.Store(&credentials{
+		clientID:       clientID,
+		clientSecret:   clientSecret,
+		tokensEndpoint: tokensEndpoint,
+	})
+	c.markLoaded()
+	return nil
 }
 
-func (c *oauthClient) getAuthorizationToken(credentials credentials) (Token, apperrors.AppError) {
-	log.Infof("Getting authorisation token for credentials to access Director from endpoint: %s", credentials.tokensEndpoint)
+func (c *oauthClient) markLoaded() {
+	c.loadedOnce.Do(func() { close(c.loaded) })
+}
+
+func (c *oauthClient) getAuthorizationToken(ctx context.Context, credentials credentials) (Token, apperrors.AppError) {
+	logging.FromContext(ctx).Infof("Getting authorisation token for credentials to access Director from endpoint: %s", credentials.tokensEndpoint)
+
+	now := time.Now()
 
 	form := url.Values{}
 	form.Add(grantTypeFieldName, credentialsGrantType)
-	form.Add(scopeFieldName, scopes)
+	form.Add(scopeFieldName, strings.Join(c.options.Scopes, " "))
 
-	request, err := http.NewRequest(http.MethodPost, credentials.tokensEndpoint, strings.NewReader(form.Encode()))
+	useBasicAuth, err := c.authenticate(form, credentials, now)
 	if err != nil {
-		log.Errorf("Failed to create authorisation token request")
-		return Token{}, apperrors.Internalf("Failed to create authorisation token request: %s", err.Error())
+		return Token{}, apperrors.Internalf("Failed to authenticate authorisation token request: %s", err.Error()).SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
 	}
 
-	now := time.Now().Unix()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, credentials.tokensEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Failed to create authorisation token request")
+		return Token{}, apperrors.Internalf("Failed to create authorisation token request: %s", err.Error())
+	}
 
-	request.SetBasicAuth(credentials.clientID, credentials.clientSecret)
+	if useBasicAuth {
+		request.SetBasicAuth(credentials.clientID, credentials.clientSecret)
+	}
 	request.Header.Set(contentTypeHeader, contentTypeApplicationURLEncoded)
 
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return Token{}, apperrors.Internalf("Failed to execute http call: %s", err.Error())
+		return Token{}, apperrors.Internalf("Failed to execute http call: %s", err.Error()).SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
 	}
 	defer util.Close(response.Body)
 
-	if response.StatusCode != http.StatusOK {
-		dump, dumpErr := httputil.DumpResponse(response, true)
-		if dumpErr != nil {
-			dump = []byte("failed to dump response body")
-		}
-		return Token{}, apperrors.External("Get token call returned unexpected status: %s. Response dump: %s", response.Status, string(dump)).SetComponent(apperrors.ErrMpsOAuth2)
-	}
-
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		return Token{}, apperrors.Internalf("Failed to read token response body from '%s': %s", credentials.tokensEndpoint, err.Error())
 	}
 
+	if response.StatusCode != http.StatusOK {
+		return Token{}, toOAuthAppError(response.Status, body)
+	}
+
 	tokenResponse := Token{}
 
 	err = json.Unmarshal(body, &tokenResponse)
@@ -83,9 +156,75 @@ func (c *oauthClient) getAuthorizationToken(credentials credentials) (Token, app
 		return Token{}, apperrors.Internalf("failed to unmarshal token response body: %s", err.Error())
 	}
 
-	log.Infof("Successfully unmarshal response oauth token for accessing Director")
+	logging.FromContext(ctx).Infof("Successfully unmarshal response oauth token for accessing Director")
 
-	tokenResponse.Expiration += now
+	tokenResponse.Expiration += now.Unix()
 
 	return tokenResponse, nil
 }
+
+// authenticate adds the client authentication to the form, and returns true if the credentials must be sent in the Authorization header instead
+func (c *oauthClient) authenticate(form url.Values, credentials credentials, now time.Time) (bool, error) {
+	switch c.options.AuthStyle {
+	case AuthStyleClientSecretBasic:
+		return true, nil
+	case AuthStyleClientSecretPost:
+		form.Add(clientIDFieldName, credentials.clientID)
+		form.Add(clientSecretFieldName, credentials.clientSecret)
+	case AuthStylePrivateKeyJWT:
+		if c.options.PrivateKey == nil {
+			return false, errors.New("private key is required to sign the client assertion")
+		}
+		assertion, err := clientAssertion(c.options.PrivateKey, c.options.KeyID, credentials, now)
+		if err != nil {
+			return false, err
+		}
+		form.Add(clientIDFieldName, credentials.clientID)
+		form.Add(clientAssertionTypeFieldName, jwtBearerAssertionType)
+		form.Add(clientAssertionFieldName, assertion)
+	case AuthStyleTLSClientAuth:
+		form.Add(clientIDFieldName, credentials.clientID)
+	default:
+		return false, errors.Errorf("unknown auth style %q", c.options.AuthStyle)
+	}
+	return false, nil
+}
+
+// toOAuthAppError converts the error response of the tokens endpoint to AppError with the reason matching the OAuth error code
+func toOAuthAppError(status string, body []byte) apperrors.AppError {
+	oauthErr := errorResponse{}
+	if err := json.Unmarshal(body, &oauthErr); err != nil || oauthErr.Error == "" {
+		return apperrors.External("Get token call returned unexpected status: %s. Response body: %s", status, truncate(logging.Redact(string(body)))).SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
+	}
+
+	message := fmt.Sprintf("Get token call returned status: %s, error: %s", status, oauthErr.Error)
+	if oauthErr.ErrorDescription != "" {
+		message += ", description: " + oauthErr.ErrorDescription
+	}
+	if oauthErr.ErrorURI != "" {
+		message += ", uri: " + oauthErr.ErrorURI
+	}
+	return apperrors.External("%s", logging.Redact(message)).SetComponent(apperrors.ErrMpsOAuth2).SetReason(oauthErrorReason(oauthErr.Error))
+}
+
+func oauthErrorReason(code string) apperrors.ErrReason {
+	switch code {
+	case "invalid_client", "unauthorized_client":
+		return apperrors.ErrOAuthInvalidClient
+	case "invalid_scope":
+		return apperrors.ErrOAuthInvalidScope
+	case "invalid_request", "invalid_grant", "unsupported_grant_type":
+		return apperrors.ErrOAuthInvalidRequest
+	default:
+		return apperrors.ErrOAuthTokenRequestFailed
+	}
+}
+
+// truncate shortens the body, after its secrets are redacted, so that no partial secret is left unrecognized
+func truncate(body string) string {
+	const maxLength = 512
+	if len(body) > maxLength {
+		return body[:maxLength] + "..."
+	}
+	return body
+}
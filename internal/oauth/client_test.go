@@ -3,12 +3,24 @@ package oauth
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"math/big"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
@@ -62,7 +74,7 @@ func TestOauthClient_GetAuthorizationToken(t *testing.T) {
 		oauthClient := NewOauthClient(client, credentials.clientID, credentials.clientSecret, credentials.tokensEndpoint)
 
 		// when
-		responseToken, err := oauthClient.GetAuthorizationToken()
+		responseToken, err := oauthClient.GetAuthorizationToken(context.Background())
 		require.NoError(t, err)
 		token.Expiration += time.Now().Unix()
 
@@ -72,6 +84,288 @@ func TestOauthClient_GetAuthorizationToken(t *testing.T) {
 	})
 }
 
+func TestOauthClient_AuthStyles(t *testing.T) {
+	credentials := credentials{ //nolint:govet
+		clientID:       "12345",
+		clientSecret:   "some dark and scary secret",
+		tokensEndpoint: "http://hydra:4445",
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		description string
+		options     Options
+		verify      func(t *testing.T, req *http.Request)
+	}{
+		{
+			description: "Should send credentials in the request body with client_secret_post",
+			options:     Options{AuthStyle: AuthStyleClientSecretPost, Scopes: []string{"scope1", "scope2"}},
+			verify: func(t *testing.T, req *http.Request) {
+				_, _, ok := req.BasicAuth()
+				assert.False(t, ok)
+				assert.Equal(t, credentials.clientID, req.PostForm.Get(clientIDFieldName))
+				assert.Equal(t, credentials.clientSecret, req.PostForm.Get(clientSecretFieldName))
+				assert.Equal(t, "scope1 scope2", req.PostForm.Get(scopeFieldName))
+			},
+		},
+		{
+			description: "Should send client assertion signed with RSA key with private_key_jwt",
+			options:     Options{AuthStyle: AuthStylePrivateKeyJWT, PrivateKey: rsaKey, KeyID: "key-1"},
+			verify: func(t *testing.T, req *http.Request) {
+				assert.Empty(t, req.PostForm.Get(clientSecretFieldName))
+				assert.Equal(t, jwtBearerAssertionType, req.PostForm.Get(clientAssertionTypeFieldName))
+				verifyClientAssertion(t, req.PostForm.Get(clientAssertionFieldName), &rsaKey.PublicKey, "RS256", credentials)
+			},
+		},
+		{
+			description: "Should send client assertion signed with ECDSA key with private_key_jwt",
+			options:     Options{AuthStyle: AuthStylePrivateKeyJWT, PrivateKey: ecKey},
+			verify: func(t *testing.T, req *http.Request) {
+				verifyClientAssertion(t, req.PostForm.Get(clientAssertionFieldName), &ecKey.PublicKey, "ES256", credentials)
+			},
+		},
+		{
+			description: "Should send only client ID with tls_client_auth",
+			options:     Options{AuthStyle: AuthStyleTLSClientAuth},
+			verify: func(t *testing.T, req *http.Request) {
+				_, _, ok := req.BasicAuth()
+				assert.False(t, ok)
+				assert.Equal(t, credentials.clientID, req.PostForm.Get(clientIDFieldName))
+				assert.Empty(t, req.PostForm.Get(clientSecretFieldName))
+				assert.Equal(t, "runtime:read runtime:write", req.PostForm.Get(scopeFieldName))
+			},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			// given
+			client := NewTestClient(func(req *http.Request) *http.Response {
+				require.NoError(t, req.ParseForm())
+				testCase.verify(t, req)
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"access_token": "12345", "expires_in": 1234}`))),
+				}
+			})
+			oauthClient := NewOauthClientWithOptions(client, testCase.options, credentials.clientID, credentials.clientSecret, credentials.tokensEndpoint)
+
+			// when
+			token, err := oauthClient.GetAuthorizationToken(context.Background())
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "12345", token.AccessToken)
+		})
+	}
+}
+
+func TestOauthClient_ErrorResponse(t *testing.T) {
+	for _, testCase := range []struct {
+		description    string
+		status         int
+		body           string
+		expectedReason apperrors.ErrReason
+		expectedText   string
+	}{
+		{
+			description:    "Should parse invalid_client error",
+			status:         http.StatusUnauthorized,
+			body:           `{"error": "invalid_client", "error_description": "Client authentication failed"}`,
+			expectedReason: apperrors.ErrOAuthInvalidClient,
+			expectedText:   "error: invalid_client, description: Client authentication failed",
+		},
+		{
+			description:    "Should parse invalid_scope error",
+			status:         http.StatusBadRequest,
+			body:           `{"error": "invalid_scope"}`,
+			expectedReason: apperrors.ErrOAuthInvalidScope,
+			expectedText:   "error: invalid_scope",
+		},
+		{
+			description:    "Should return the body of the response which is not OAuth error",
+			status:         http.StatusBadGateway,
+			body:           "upstream unavailable",
+			expectedReason: apperrors.ErrOAuthTokenRequestFailed,
+			expectedText:   "Response body: upstream unavailable",
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			// given
+			client := NewTestClient(func(req *http.Request) *http.Response {
+				return &http.Response{
+					StatusCode: testCase.status,
+					Status:     http.StatusText(testCase.status),
+					Body:       io.NopCloser(bytes.NewReader([]byte(testCase.body))),
+				}
+			})
+			oauthClient := NewOauthClient(client, "12345", "secret", "http://hydra:4445")
+
+			// when
+			_, err := oauthClient.GetAuthorizationToken(context.Background())
+
+			// then
+			require.Error(t, err)
+			assert.Equal(t, apperrors.ErrMpsOAuth2, err.Component())
+			assert.Equal(t, testCase.expectedReason, err.Reason())
+			assert.Contains(t, err.Error(), testCase.expectedText)
+		})
+	}
+}
+
+func TestOauthClient_Redaction(t *testing.T) {
+	const (
+		clientSecret = "client-secret-value"
+		accessToken  = "access-token-value"
+	)
+
+	for _, testCase := range []struct {
+		description string
+		status      int
+		body        string
+	}{
+		{
+			description: "Should redact the token returned with unexpected status",
+			status:      http.StatusInternalServerError,
+			body:        `{"access_token": "` + accessToken + `", "token_type": "bearer"}`,
+		},
+		{
+			description: "Should redact the credentials echoed in the OAuth error",
+			status:      http.StatusBadRequest,
+			body:        `{"error": "invalid_request", "error_description": "unexpected client_secret=` + clientSecret + `"}`,
+		},
+		{
+			description: "Should not log the token",
+			status:      http.StatusOK,
+			body:        `{"access_token": "` + accessToken + `", "expires_in": 3600}`,
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			// given
+			logger := logrus.New()
+			require.NoError(t, logging.Configure(logger, logging.Config{Level: "trace"}))
+			output := &bytes.Buffer{}
+			logger.SetOutput(output)
+			ctx := logging.IntoContext(context.Background(), logrus.NewEntry(logger))
+
+			client := NewTestClient(func(req *http.Request) *http.Response {
+				return &http.Response{
+					StatusCode: testCase.status,
+					Status:     http.StatusText(testCase.status),
+					Body:       io.NopCloser(strings.NewReader(testCase.body)),
+				}
+			})
+			oauthClient := NewOauthClientWithOptions(client, Options{AuthStyle: AuthStyleClientSecretPost}, "12345", clientSecret, "http://hydra:4445")
+
+			// when
+			_, err := oauthClient.GetAuthorizationToken(ctx)
+			if err != nil {
+				logger.WithError(err).Error("Failed to get token")
+			}
+
+			// then
+			for _, secret := range []string{clientSecret, accessToken} {
+				assert.NotContains(t, output.String(), secret)
+				if err != nil {
+					assert.NotContains(t, err.Error(), secret)
+				}
+			}
+		})
+	}
+}
+
+func TestOauthClient_WaitForCredentials(t *testing.T) {
+	requests := 0
+	client := NewTestClient(func(*http.Request) *http.Response {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"access_token":"12345","expires_in":3600}`)),
+		}
+	})
+
+	t.Run("Should not request token before credentials are loaded", func(t *testing.T) {
+		oauthClient := NewOauthClient(client, "", "", "")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := oauthClient.GetAuthorizationToken(ctx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "credentials not loaded")
+		assert.Zero(t, requests)
+	})
+
+	t.Run("Should request token once credentials are loaded", func(t *testing.T) {
+		oauthClient := NewOauthClient(client, "", "", "")
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			assert.NoError(t, oauthClient.UpdateCredentials("id", "secret", "http://hydra:4445"))
+		}()
+
+		token, err := oauthClient.GetAuthorizationToken(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "12345", token.AccessToken)
+		assert.Equal(t, 1, requests)
+	})
+}
+
+func TestOauthClient_UpdateCredentials(t *testing.T) {
+	t.Run("Should require client secret with client_secret_basic", func(t *testing.T) {
+		oauthClient := NewOauthClient(&http.Client{}, "", "", "")
+
+		assert.Error(t, oauthClient.UpdateCredentials("id", "", "http://hydra:4445"))
+		assert.NoError(t, oauthClient.UpdateCredentials("id", "secret", "http://hydra:4445"))
+	})
+
+	t.Run("Should not require client secret with private_key_jwt", func(t *testing.T) {
+		oauthClient := NewOauthClientWithOptions(&http.Client{}, Options{AuthStyle: AuthStylePrivateKeyJWT}, "", "", "")
+
+		assert.NoError(t, oauthClient.UpdateCredentials("id", "", "http://hydra:4445"))
+		assert.Error(t, oauthClient.UpdateCredentials("", "", "http://hydra:4445"))
+	})
+}
+
+func verifyClientAssertion(t *testing.T, assertion string, key crypto.PublicKey, expectedAlg string, credentials credentials) {
+	parts := strings.Split(assertion, ".")
+	require.Len(t, parts, 3)
+
+	header := map[string]string{}
+	decodeSegment(t, parts[0], &header)
+	assert.Equal(t, expectedAlg, header["alg"])
+
+	claims := map[string]interface{}{}
+	decodeSegment(t, parts[1], &claims)
+	assert.Equal(t, credentials.clientID, claims["iss"])
+	assert.Equal(t, credentials.clientID, claims["sub"])
+	assert.Equal(t, credentials.tokensEndpoint, claims["aud"])
+	assert.NotEmpty(t, claims["jti"])
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		assert.NoError(t, rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature))
+	case *ecdsa.PublicKey:
+		require.Len(t, signature, 64)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		assert.True(t, ecdsa.Verify(k, digest[:], r, s))
+	}
+}
+
+func decodeSegment(t *testing.T, segment string, value interface{}) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, value))
+}
+
 func NewTestClient(fn RoundTripFunc) *http.Client {
 	return &http.Client{
 		Transport: fn,
@@ -0,0 +1,190 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// directorOAuth is the format of the file with the Director OAuth credentials
+type directorOAuth struct {
+	Data struct {
+		ClientID       string `json:"client_id"`
+		ClientSecret   string `json:"client_secret"`
+		TokensEndpoint string `json:"tokens_endpoint"`
+	} `json:"data"`
+}
+
+// CredentialsWatcher updates the credentials of the OAuth client whenever the file or the secret they are stored in changes.
+// The credentials are reported as invalid by Check until correct ones are loaded.
+type CredentialsWatcher struct {
+	Log    *log.Logger
+	client Client
+	watch  func(ctx context.Context, w *CredentialsWatcher) error
+
+	mu      sync.RWMutex
+	data    []byte
+	loadErr error
+}
+
+// NewFileCredentialsWatcher creates CredentialsWatcher reading the credentials from the file, which is watched for changes with fsnotify
+func NewFileCredentialsWatcher(path string, client Client, log *log.Logger) *CredentialsWatcher {
+	return &CredentialsWatcher{
+		Log:     log,
+		client:  client,
+		watch:   watchFile(path),
+		loadErr: errors.New("credentials not loaded yet"),
+	}
+}
+
+// NewSecretCredentialsWatcher creates CredentialsWatcher reading the credentials from the key of the secret, which is watched with the informer of the cache
+func NewSecretCredentialsWatcher(informers cache.Informers, name types.NamespacedName, key string, client Client, log *log.Logger) *CredentialsWatcher {
+	return &CredentialsWatcher{
+		Log:     log,
+		client:  client,
+		watch:   watchSecret(informers, name, key),
+		loadErr: errors.New("credentials not loaded yet"),
+	}
+}
+
+// Start watches the credentials until the context is cancelled. It implements manager.Runnable.
+func (w *CredentialsWatcher) Start(ctx context.Context) error {
+	return w.watch(ctx, w)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the credentials are watched in every replica
+func (w *CredentialsWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Check returns the error if the credentials couldn't be loaded. It implements healthz.Checker.
+func (w *CredentialsWatcher) Check(_ *http.Request) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return errors.Wrap(w.loadErr, "Director credentials are invalid")
+}
+
+// Update parses the credentials, and swaps them in the OAuth client if they changed and are valid
+func (w *CredentialsWatcher) Update(data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.loadErr == nil && bytes.Equal(w.data, data) {
+		return
+	}
+
+	cfg := directorOAuth{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		w.invalidate(errors.Wrap(err, "failed to unmarshal Director credentials"))
+		return
+	}
+	if err := w.client.UpdateCredentials(cfg.Data.ClientID, cfg.Data.ClientSecret, cfg.Data.TokensEndpoint); err != nil {
+		w.invalidate(errors.Wrap(err, "invalid Director credentials"))
+		return
+	}
+
+	w.data = data
+	w.loadErr = nil
+	w.Log.Infof("Director credentials loaded")
+}
+
+func (w *CredentialsWatcher) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.invalidate(err)
+}
+
+func (w *CredentialsWatcher) invalidate(err error) {
+	w.Log.Warnf("Failed to load Director credentials, using the previous ones: %v", err)
+	w.loadErr = err
+}
+
+func watchFile(path string) func(ctx context.Context, w *CredentialsWatcher) error {
+	return func(ctx context.Context, w *CredentialsWatcher) error {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return errors.Wrap(err, "failed to create watcher of Director credentials")
+		}
+		defer watcher.Close()
+
+		// the directory is watched, as the files mounted from secrets are replaced by swapping symlinks
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return errors.Wrapf(err, "failed to watch Director credentials file %s", path)
+		}
+
+		w.Log.Infof("Watching Director credentials file %s", path)
+		w.loadFile(path)
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-watcher.Events:
+				w.loadFile(path)
+			case err := <-watcher.Errors:
+				w.Log.Warnf("Error while watching Director credentials file %s: %v", path, err)
+			}
+		}
+	}
+}
+
+func (w *CredentialsWatcher) loadFile(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		w.fail(errors.Wrap(err, "failed to read Director credentials file"))
+		return
+	}
+	w.Update(data)
+}
+
+func watchSecret(informers cache.Informers, name types.NamespacedName, key string) func(ctx context.Context, w *CredentialsWatcher) error {
+	return func(ctx context.Context, w *CredentialsWatcher) error {
+		informer, err := informers.GetInformer(ctx, &corev1.Secret{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get informer for Director credentials secret")
+		}
+
+		update := func(obj interface{}) {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok || secret.Name != name.Name || secret.Namespace != name.Namespace {
+				return
+			}
+			data, ok := secret.Data[key]
+			if !ok {
+				w.fail(errors.Errorf("secret %s doesn't contain the %s key", name, key))
+				return
+			}
+			w.Update(data)
+		}
+
+		registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    update,
+			UpdateFunc: func(_, newObj interface{}) { update(newObj) },
+			DeleteFunc: func(obj interface{}) {
+				if secret, ok := obj.(*corev1.Secret); ok && secret.Name == name.Name && secret.Namespace == name.Namespace {
+					w.fail(errors.Errorf("secret %s with Director credentials was deleted", name))
+				}
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to watch Director credentials secret")
+		}
+
+		w.Log.Infof("Watching Director credentials secret %s", name)
+		<-ctx.Done()
+		return informer.RemoveEventHandler(registration)
+	}
+}
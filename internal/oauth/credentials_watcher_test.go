@@ -0,0 +1,73 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validCredentials = `data:
+  client_id: id
+  client_secret: secret
+  tokens_endpoint: https://tokens.example.com
+`
+
+func TestCredentialsWatcher(t *testing.T) {
+	t.Run("Should update credentials of the client and become ready", func(t *testing.T) {
+		// given
+		client := NewOauthClient(&http.Client{}, "", "", "").(*oauthClient) //nolint:forcetypeassert
+		watcher := NewFileCredentialsWatcher("", client, log.New())
+		require.Error(t, watcher.Check(nil))
+
+		// when
+		watcher.Update([]byte(validCredentials))
+
+		// then
+		require.NoError(t, watcher.Check(nil))
+		assert.Equal(t, credentials{clientID: "id", clientSecret: "secret", tokensEndpoint: "https://tokens.example.com"}, *client.creds.Load())
+	})
+
+	t.Run("Should keep previous credentials and become not ready when new ones are invalid", func(t *testing.T) {
+		// given
+		client := NewOauthClient(&http.Client{}, "", "", "").(*oauthClient) //nolint:forcetypeassert
+		watcher := NewFileCredentialsWatcher("", client, log.New())
+		watcher.Update([]byte(validCredentials))
+
+		// when
+		watcher.Update([]byte("data:\n  client_id: other\n"))
+
+		// then
+		require.Error(t, watcher.Check(nil))
+		assert.Equal(t, "id", client.creds.Load().clientID)
+	})
+
+	t.Run("Should reload credentials when the file changes", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "director.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(validCredentials), 0o600))
+
+		client := NewOauthClient(&http.Client{}, "", "", "").(*oauthClient) //nolint:forcetypeassert
+		watcher := NewFileCredentialsWatcher(path, client, log.New())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			assert.NoError(t, watcher.Start(ctx))
+		}()
+		require.Eventually(t, func() bool { return watcher.Check(nil) == nil }, 5*time.Second, 10*time.Millisecond)
+
+		// when
+		rotated := `data: {client_id: rotated, client_secret: secret, tokens_endpoint: "https://tokens.example.com"}`
+		require.NoError(t, os.WriteFile(path, []byte(rotated), 0o600))
+
+		// then
+		require.Eventually(t, func() bool { return client.creds.Load().clientID == "rotated" }, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	apperrors "github.com/kyma-project/compass-manager/internal/apperrors"
 	oauth "github.com/kyma-project/compass-manager/internal/oauth"
 	mock "github.com/stretchr/testify/mock"
@@ -13,23 +15,23 @@ type Client struct {
 	mock.Mock
 }
 
-// GetAuthorizationToken provides a mock function with given fields:
-func (_m *Client) GetAuthorizationToken() (oauth.Token, apperrors.AppError) {
-	ret := _m.Called()
+// GetAuthorizationToken provides a mock function with given fields: ctx
+func (_m *Client) GetAuthorizationToken(ctx context.Context) (oauth.Token, apperrors.AppError) {
+	ret := _m.Called(ctx)
 
 	var r0 oauth.Token
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(0).(func() (oauth.Token, apperrors.AppError)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) (oauth.Token, apperrors.AppError)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() oauth.Token); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) oauth.Token); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Get(0).(oauth.Token)
 	}
 
-	if rf, ok := ret.Get(1).(func() apperrors.AppError); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) apperrors.AppError); ok {
+		r1 = rf(ctx)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -39,6 +41,20 @@ func (_m *Client) GetAuthorizationToken() (oauth.Token, apperrors.AppError) {
 	return r0, r1
 }
 
+// UpdateCredentials provides a mock function with given fields: clientID, clientSecret, tokensEndpoint
+func (_m *Client) UpdateCredentials(clientID string, clientSecret string, tokensEndpoint string) error {
+	ret := _m.Called(clientID, clientSecret, tokensEndpoint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(clientID, clientSecret, tokensEndpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClient(t interface {
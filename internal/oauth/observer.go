@@ -0,0 +1,33 @@
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+)
+
+// TokenObserver is notified about every finished token request, e.g. to record its latency and failures as metrics
+type TokenObserver interface {
+	ObserveTokenRequest(duration time.Duration, err apperrors.AppError)
+}
+
+type observedClient struct {
+	Client
+	observer TokenObserver
+}
+
+// NewObservedClient returns Client notifying the observer about every token request of client
+func NewObservedClient(client Client, observer TokenObserver) Client {
+	return &observedClient{
+		Client:   client,
+		observer: observer,
+	}
+}
+
+func (c *observedClient) GetAuthorizationToken(ctx context.Context) (Token, apperrors.AppError) {
+	start := time.Now()
+	token, err := c.Client.GetAuthorizationToken(ctx)
+	c.observer.ObserveTokenRequest(time.Since(start), err)
+	return token, err
+}
@@ -0,0 +1,112 @@
+package oauth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"golang.org/x/sync/singleflight"
+)
+
+// TokenSource provides the token to access Director
+type TokenSource interface {
+	// Token returns the cached token. A new token is fetched when the cached one expires within the refresh skew,
+	// and the cached one is returned if that fails before it expires.
+	Token(ctx context.Context) (Token, apperrors.AppError)
+	// Invalidate drops the cached token if it's the given one, e.g. after it was rejected, so that the next call to Token fetches a new one
+	Invalidate(token Token)
+}
+
+type cachedTokenSource struct {
+	client      Client
+	refreshSkew time.Duration
+
+	mu    sync.RWMutex
+	token Token
+	group singleflight.Group
+}
+
+// NewTokenSource creates TokenSource which is safe for concurrent use. Concurrent callers share a single token request,
+// and the token is refreshed refreshSkew before it expires, so that it doesn't expire while the request using it is in flight.
+func NewTokenSource(client Client, refreshSkew time.Duration) TokenSource {
+	return &cachedTokenSource{
+		client:      client,
+		refreshSkew: refreshSkew,
+	}
+}
+
+func (s *cachedTokenSource) Token(ctx context.Context) (Token, apperrors.AppError) {
+	s.mu.RLock()
+	token := s.token
+	s.mu.RUnlock()
+
+	if !token.EmptyOrExpiresWithin(s.refreshSkew) {
+		return token, nil
+	}
+
+	// the token is requested without the cancellation of the caller, as the request is shared with other callers
+	result := s.group.DoChan("token", func() (interface{}, error) {
+		return s.refresh(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return Token{}, apperrors.Internalf("Failed to obtain token: %v", ctx.Err()).SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
+	case res := <-result:
+		if res.Err != nil {
+			return Token{}, res.Err.(apperrors.AppError) //nolint:errorlint,forcetypeassert
+		}
+		return res.Val.(Token), nil //nolint:forcetypeassert
+	}
+}
+
+func (s *cachedTokenSource) refresh(ctx context.Context) (Token, error) {
+	s.mu.RLock()
+	token := s.token
+	s.mu.RUnlock()
+
+	// another caller may have refreshed the token in the meantime
+	if !token.EmptyOrExpiresWithin(s.refreshSkew) {
+		return token, nil
+	}
+
+	logging.FromContext(ctx).Infof("Refreshing token to access Director Service")
+	newToken, err := s.fetch(ctx)
+	if err != nil {
+		// the proactive refresh failed, the cached token is used until it expires
+		if !token.EmptyOrExpired() {
+			logging.FromContext(ctx).Warnf("Failed to refresh token to access Director Service, using the cached one until it expires: %v", err)
+			return token, nil
+		}
+		return Token{}, err
+	}
+
+	s.mu.Lock()
+	s.token = newToken
+	s.mu.Unlock()
+
+	return newToken, nil
+}
+
+func (s *cachedTokenSource) fetch(ctx context.Context) (Token, apperrors.AppError) {
+	token, err := s.client.GetAuthorizationToken(ctx)
+	if err != nil {
+		return Token{}, err.Append("Error while obtaining token")
+	}
+
+	if token.EmptyOrExpired() {
+		return Token{}, apperrors.Internal("Obtained empty or expired token").SetComponent(apperrors.ErrMpsOAuth2).SetReason(apperrors.ErrOAuthTokenRequestFailed)
+	}
+	return token, nil
+}
+
+func (s *cachedTokenSource) Invalidate(token Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken == token.AccessToken {
+		s.token = Token{}
+	}
+}
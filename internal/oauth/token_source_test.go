@@ -0,0 +1,138 @@
+package oauth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/compass-manager/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingClient struct {
+	mu     sync.Mutex
+	calls  int
+	tokens []Token
+	// errs fail the calls following the ones returning the tokens
+	errs []apperrors.AppError
+}
+
+func (c *countingClient) GetAuthorizationToken(_ context.Context) (Token, apperrors.AppError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	call := c.calls
+	c.calls++
+	if len(c.errs) > 0 && call >= len(c.tokens) {
+		return Token{}, c.errs[(call-len(c.tokens))%len(c.errs)]
+	}
+	return c.tokens[call%len(c.tokens)], nil
+}
+
+func (c *countingClient) UpdateCredentials(_, _, _ string) error { return nil }
+
+func TestTokenSource(t *testing.T) {
+	validToken := Token{AccessToken: "valid", Expiration: time.Now().Add(time.Hour).Unix()}
+
+	t.Run("Should share a single token request between concurrent callers", func(t *testing.T) {
+		// given
+		client := &countingClient{tokens: []Token{validToken}}
+		source := NewTokenSource(client, time.Minute)
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := source.Token(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, validToken, token)
+			}()
+		}
+		wg.Wait()
+
+		// then
+		assert.Equal(t, 1, client.calls)
+	})
+
+	t.Run("Should refresh token expiring within the refresh skew", func(t *testing.T) {
+		// given
+		expiringToken := Token{AccessToken: "expiring", Expiration: time.Now().Add(30 * time.Second).Unix()}
+		client := &countingClient{tokens: []Token{expiringToken, validToken}}
+		source := NewTokenSource(client, time.Minute)
+
+		// when
+		_, err := source.Token(context.Background())
+		require.NoError(t, err)
+		token, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, validToken, token)
+		assert.Equal(t, 2, client.calls)
+	})
+
+	t.Run("Should fetch a new token only when the cached one is invalidated", func(t *testing.T) {
+		// given
+		newToken := Token{AccessToken: "new", Expiration: time.Now().Add(time.Hour).Unix()}
+		client := &countingClient{tokens: []Token{validToken, newToken}}
+		source := NewTokenSource(client, 0)
+		_, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		// when
+		source.Invalidate(Token{AccessToken: "other"})
+		cached, err := source.Token(context.Background())
+		require.NoError(t, err)
+		source.Invalidate(validToken)
+		refreshed, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, validToken, cached)
+		assert.Equal(t, newToken, refreshed)
+	})
+
+	t.Run("Should return error when obtained token is expired", func(t *testing.T) {
+		// given
+		client := &countingClient{tokens: []Token{{AccessToken: "expired", Expiration: time.Now().Add(-time.Hour).Unix()}}}
+		source := NewTokenSource(client, 0)
+
+		// when
+		_, err := source.Token(context.Background())
+
+		// then
+		assert.Error(t, err)
+	})
+	t.Run("Should return cached token when refresh fails before it expires", func(t *testing.T) {
+		// given
+		expiringToken := Token{AccessToken: "expiring", Expiration: time.Now().Add(30 * time.Second).Unix()}
+		client := &countingClient{tokens: []Token{expiringToken}, errs: []apperrors.AppError{apperrors.External("OAuth server unavailable")}}
+		source := NewTokenSource(client, time.Minute)
+		_, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		// when
+		token, err := source.Token(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, expiringToken, token)
+		assert.Equal(t, 2, client.calls)
+	})
+
+	t.Run("Should return error when refresh fails after cached token expired", func(t *testing.T) {
+		// given
+		client := &countingClient{errs: []apperrors.AppError{apperrors.External("OAuth server unavailable")}}
+		source := &cachedTokenSource{client: client, refreshSkew: time.Minute, token: Token{AccessToken: "expired", Expiration: time.Now().Add(-time.Second).Unix()}}
+
+		// when
+		_, err := source.Token(context.Background())
+
+		// then
+		assert.ErrorContains(t, err, "OAuth server unavailable")
+	})
+}
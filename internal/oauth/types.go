@@ -1,6 +1,9 @@
 package oauth
 
-import "time"
+import (
+	"crypto"
+	"time"
+)
 
 const (
 	contentTypeHeader                = "Content-Type"
@@ -10,13 +13,52 @@ const (
 	credentialsGrantType = "client_credentials"
 
 	scopeFieldName = "scope"
-	scopes         = "runtime:read runtime:write"
+
+	clientIDFieldName            = "client_id"
+	clientSecretFieldName        = "client_secret"
+	clientAssertionTypeFieldName = "client_assertion_type"
+	clientAssertionFieldName     = "client_assertion"
+	jwtBearerAssertionType       = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
 
 	clientIDKey       = "client_id"
 	clientSecretKey   = "client_secret"
 	tokensEndpointKey = "tokens_endpoint"
 )
 
+// AuthStyle is the method authenticating the client to the tokens endpoint, as registered in the OAuth Token Endpoint Authentication Methods registry
+type AuthStyle string
+
+const (
+	// AuthStyleClientSecretBasic sends the client credentials in the Authorization header
+	AuthStyleClientSecretBasic AuthStyle = "client_secret_basic"
+	// AuthStyleClientSecretPost sends the client credentials in the request body
+	AuthStyleClientSecretPost AuthStyle = "client_secret_post"
+	// AuthStylePrivateKeyJWT sends the client assertion signed with the private key, as defined in RFC 7523
+	AuthStylePrivateKeyJWT AuthStyle = "private_key_jwt"
+	// AuthStyleTLSClientAuth authenticates the client with the certificate of the mTLS connection, as defined in RFC 8705
+	AuthStyleTLSClientAuth AuthStyle = "tls_client_auth"
+)
+
+// DefaultScopes are the scopes requested to access Director
+var DefaultScopes = []string{"runtime:read", "runtime:write"} //nolint:gochecknoglobals
+
+// Options configures how the token is requested
+type Options struct {
+	Scopes    []string
+	AuthStyle AuthStyle
+	// PrivateKey signs the client assertion with AuthStylePrivateKeyJWT
+	PrivateKey crypto.Signer
+	// KeyID is the optional `kid` header of the client assertion
+	KeyID string
+}
+
+// errorResponse is the error returned by the tokens endpoint, as defined in RFC 6749
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorURI         string `json:"error_uri"`
+}
+
 type Token struct {
 	AccessToken string `json:"access_token"`
 	Expiration  int64  `json:"expires_in"`
@@ -29,10 +71,15 @@ type credentials struct {
 }
 
 func (token Token) EmptyOrExpired() bool {
+	return token.EmptyOrExpiresWithin(0)
+}
+
+// EmptyOrExpiresWithin returns true if the token is empty, or it expires within the given duration
+func (token Token) EmptyOrExpiresWithin(duration time.Duration) bool {
 	if token.AccessToken == "" {
 		return true
 	}
 
 	expiration := time.Unix(token.Expiration, 0)
-	return time.Now().After(expiration)
+	return time.Now().Add(duration).After(expiration)
 }
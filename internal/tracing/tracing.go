@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName = "compass-manager"
+
+	// ExporterNone disables tracing
+	ExporterNone = "none"
+	// ExporterOTLP sends the spans over OTLP/HTTP, e.g. to a local collector
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes the spans to the standard output
+	ExporterStdout = "stdout"
+
+	AttributeKymaName         = attribute.Key("kyma.name")
+	AttributeKymaNamespace    = attribute.Key("kyma.namespace")
+	AttributeRuntimeID        = attribute.Key("compass.runtime.id")
+	AttributeTenant           = attribute.Key("compass.tenant")
+	AttributeDirectorBackend  = attribute.Key("compass.director.backend")
+	AttributeGraphQLOperation = attribute.Key("graphql.operation.name")
+	AttributeOAuthAuthStyle   = attribute.Key("oauth.auth_style")
+	AttributeSecretName       = attribute.Key("k8s.secret.name")
+	AttributeSecretNamespace  = attribute.Key("k8s.secret.namespace")
+
+	tracerName = "github.com/kyma-project/compass-manager"
+)
+
+// Config chooses where the spans are exported
+type Config struct {
+	// Exporter is one of ExporterNone, ExporterOTLP and ExporterStdout
+	Exporter string
+	// Endpoint is the host and port of the OTLP/HTTP receiver. When empty, the OTEL_EXPORTER_OTLP_* environment variables apply.
+	Endpoint string
+	// Insecure sends the spans to the OTLP receiver without TLS
+	Insecure bool
+	// SampleRatio is the ratio of the traces started by Compass Manager which are sampled
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider exporting the spans as configured, and the W3C Trace Context propagator.
+// The returned function flushes the spans which were not exported yet, and must be called before the process exits.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{}
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, errors.Errorf("unknown tracing exporter %q, expected %q, %q or %q", config.Exporter, ExporterNone, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create tracing exporter")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts the span of Compass Manager. Until Setup is called, the span is not recorded.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error of the operation in the span, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectHeaders propagates the trace context of ctx to the server receiving the request with the headers
+func InjectHeaders(ctx context.Context, headers http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("should fail for unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+
+		require.Error(t, err)
+	})
+
+	t.Run("should not install tracer provider when tracing is disabled", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+		require.NoError(t, err)
+
+		_, span := Start(context.Background(), "operation")
+		defer span.End()
+
+		assert.False(t, span.IsRecording())
+		assert.NoError(t, shutdown(context.Background()))
+	})
+}
+
+func TestEnd(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "failed", AttributeTenant.String("tenant"))
+	End(span, errors.New("boom"))
+	_, span = Start(context.Background(), "succeeded")
+	End(span, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Contains(t, spans[0].Attributes(), AttributeTenant.String("tenant"))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInjectHeaders(t *testing.T) {
+	recordSpans(t)
+
+	t.Run("should propagate trace context of the span", func(t *testing.T) {
+		ctx, span := Start(context.Background(), "operation")
+		defer span.End()
+		headers := http.Header{}
+
+		InjectHeaders(ctx, headers)
+
+		assert.Contains(t, headers.Get("traceparent"), span.SpanContext().TraceID().String())
+	})
+
+	t.Run("should not add headers without span", func(t *testing.T) {
+		headers := http.Header{}
+
+		InjectHeaders(context.Background(), headers)
+
+		assert.Empty(t, headers)
+	})
+}
+
+// recordSpans installs the tracer provider recording the spans in memory until the test ends
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	_, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/compass-manager/api/v1beta1"
+	"github.com/kyma-project/compass-manager/api/v1beta2"
 	"github.com/kyma-project/compass-manager/controllers"
 	"github.com/kyma-project/compass-manager/controllers/metrics"
 	"github.com/kyma-project/compass-manager/internal/director"
 	"github.com/kyma-project/compass-manager/internal/graphql"
+	"github.com/kyma-project/compass-manager/internal/logging"
+	"github.com/kyma-project/compass-manager/internal/mtls"
 	"github.com/kyma-project/compass-manager/internal/oauth"
+	"github.com/kyma-project/compass-manager/internal/tracing"
 	kyma "github.com/kyma-project/lifecycle-manager/api/v1beta2"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -22,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -29,9 +37,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
-	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
@@ -40,34 +50,114 @@ var (
 )
 
 type config struct {
-	Address                      string `envconfig:"default=127.0.0.1:3000"`
-	APIEndpoint                  string `envconfig:"default=/graphql"`
-	SkipDirectorCertVerification bool   `envconfig:"default=false"`
-	DirectorURL                  string `envconfig:"APP_DIRECTOR_URL,default=https://compass-gateway-auth-oauth.cmp-main.dev.kyma.cloud.sap/director/graphql"`
-	DirectorOAuthPath            string `envconfig:"APP_DIRECTOR_OAUTH_PATH,default=./dev/director.yaml"`
-	ConnectorURLPattern          string `envconfig:"APP_CONNECTOR_URL_PATTERN,default=kyma.cloud.sap/connector/graphql"`
-	EnabledRegistration          bool   `envconfig:"APP_ENABLED_REGISTRATION,default=false"`
-	DryRun                       bool   `envconfig:"APP_DRYRUN,default=false"`
+	Address                      string        `envconfig:"default=127.0.0.1:3000"`
+	APIEndpoint                  string        `envconfig:"default=/graphql"`
+	SkipDirectorCertVerification bool          `envconfig:"default=false"`
+	DirectorURL                  string        `envconfig:"APP_DIRECTOR_URL,default=https://compass-gateway-auth-oauth.cmp-main.dev.kyma.cloud.sap/director/graphql"`
+	DirectorOAuthPath            string        `envconfig:"APP_DIRECTOR_OAUTH_PATH,default=./dev/director.yaml"`
+	DirectorOAuthSecret          string        `envconfig:"APP_DIRECTOR_OAUTH_SECRET,optional"`
+	DirectorOAuthSecretKey       string        `envconfig:"APP_DIRECTOR_OAUTH_SECRET_KEY,default=director.yaml"`
+	DirectorOAuthScopes          string        `envconfig:"APP_DIRECTOR_OAUTH_SCOPES,default=runtime:read runtime:write"`
+	DirectorOAuthAuthStyle       string        `envconfig:"APP_DIRECTOR_OAUTH_AUTH_STYLE,default=client_secret_basic"`
+	DirectorOAuthPrivateKeyPath  string        `envconfig:"APP_DIRECTOR_OAUTH_PRIVATE_KEY_PATH,optional"`
+	DirectorOAuthKeyID           string        `envconfig:"APP_DIRECTOR_OAUTH_KEY_ID,optional"`
+	DirectorOAuthCertBound       bool          `envconfig:"APP_DIRECTOR_OAUTH_CERTIFICATE_BOUND,default=false"`
+	ConnectorURLPattern          string        `envconfig:"APP_CONNECTOR_URL_PATTERN,default=kyma.cloud.sap/connector/graphql"`
+	EnabledRegistration          bool          `envconfig:"APP_ENABLED_REGISTRATION,default=false"`
+	AdoptExistingRuntimes        bool          `envconfig:"APP_ADOPT_EXISTING_RUNTIMES,default=false"`
+	DryRun                       bool          `envconfig:"APP_DRYRUN,default=false"`
+	EnableConversionWebhook      bool          `envconfig:"APP_ENABLE_CONVERSION_WEBHOOK,default=true"`
+	AgentConfigResyncPeriod      time.Duration `envconfig:"APP_AGENT_CONFIGURATION_RESYNC_PERIOD,default=30m"`
+	RuntimeVerificationPeriod    time.Duration `envconfig:"APP_COMPASS_RUNTIME_VERIFICATION_PERIOD,default=1h"`
+	OrphanGCPeriod               time.Duration `envconfig:"APP_ORPHAN_GC_PERIOD,default=1h"`
+	OrphanGCGracePeriod          time.Duration `envconfig:"APP_ORPHAN_GC_GRACE_PERIOD,default=24h"`
+	OrphanGCDeregister           bool          `envconfig:"APP_ORPHAN_GC_DEREGISTER,default=false"`
+	MigrationTimeout             time.Duration `envconfig:"APP_MIGRATION_TIMEOUT,default=1h"`
+	MetricsMode                  string        `envconfig:"APP_METRICS_MODE,default=per-kyma"`
+	LogLevel                     string        `envconfig:"APP_LOG_LEVEL,default=info"`
+	LogFormat                    string        `envconfig:"APP_LOG_FORMAT,default=json"`
+	LogGraphQLRequests           bool          `envconfig:"APP_LOG_GRAPHQL_REQUESTS,default=false"`
+	TracingExporter              string        `envconfig:"APP_TRACING_EXPORTER,default=none"`
+	TracingOTLPEndpoint          string        `envconfig:"APP_TRACING_OTLP_ENDPOINT,optional"`
+	TracingOTLPInsecure          bool          `envconfig:"APP_TRACING_OTLP_INSECURE,default=true"`
+	TracingSampleRatio           float64       `envconfig:"APP_TRACING_SAMPLE_RATIO,default=1"`
+	ReconciliationTimeout        time.Duration `envconfig:"APP_RECONCILIATION_TIMEOUT,default=5m"`
+	RetryBaseDelay               time.Duration `envconfig:"APP_RETRY_BASE_DELAY,default=5s"`
+	RetryMaxDelay                time.Duration `envconfig:"APP_RETRY_MAX_DELAY,default=10m"`
+	RetryMaxAttempts             int           `envconfig:"APP_RETRY_MAX_ATTEMPTS,default=10"`
+	MaxConcurrentReconciles      int           `envconfig:"APP_MAX_CONCURRENT_RECONCILES,default=10"`
+	QueueQPS                     float64       `envconfig:"APP_QUEUE_QPS,default=10"`
+	QueueBurst                   int           `envconfig:"APP_QUEUE_BURST,default=100"`
+	DirectorQPS                  float64       `envconfig:"APP_DIRECTOR_QPS,default=20"`
+	DirectorBurst                int           `envconfig:"APP_DIRECTOR_BURST,default=40"`
+	DirectorGlobalAccountQPS     float64       `envconfig:"APP_DIRECTOR_GLOBAL_ACCOUNT_QPS,default=5"`
+	DirectorGlobalAccountBurst   int           `envconfig:"APP_DIRECTOR_GLOBAL_ACCOUNT_BURST,default=10"`
+	DirectorTokenRefreshSkew     time.Duration `envconfig:"APP_DIRECTOR_TOKEN_REFRESH_SKEW,default=1m"`
+	DirectorAuthMode             string        `envconfig:"APP_DIRECTOR_AUTH_MODE,default=oauth"`
+	DirectorCertSecret           string        `envconfig:"APP_DIRECTOR_CERT_SECRET,optional"`
+	DirectorCertPath             string        `envconfig:"APP_DIRECTOR_CERT_PATH,default=./dev/tls.crt"`
+	DirectorKeyPath              string        `envconfig:"APP_DIRECTOR_KEY_PATH,default=./dev/tls.key"`
+	DirectorCAPath               string        `envconfig:"APP_DIRECTOR_CA_PATH,optional"`
+	DirectorCertReloadPeriod     time.Duration `envconfig:"APP_DIRECTOR_CERT_RELOAD_PERIOD,default=1m"`
+	DirectorBackendsPath         string        `envconfig:"APP_DIRECTOR_BACKENDS_PATH,optional"`
 }
 
-func (c *config) String() string {
-	return fmt.Sprintf("Address: %s, APIEndpoint: %s, DirectorURL: %s, SkipDirectorCertVerification: %v, DirectorOAuthPath: %s",
-		c.Address, c.APIEndpoint, c.DirectorURL,
-		c.SkipDirectorCertVerification, c.DirectorOAuthPath)
+const (
+	directorAuthModeOAuth       = "oauth"
+	directorAuthModeCertificate = "certificate"
+
+	defaultDirectorBackend = "default"
+
+	// directorHTTPTimeout limits the requests to Director and to its tokens endpoint
+	directorHTTPTimeout = 30 * time.Second
+)
+
+// directorBackends is the format of the file with Director backends, and the rules routing Kyma runtimes to them
+type directorBackends struct {
+	Backends []directorBackend           `json:"backends"`
+	Routing  controllers.DirectorRouting `json:"routing"`
+}
+
+// directorBackend overrides the Director settings from environment variables for a single backend
+type directorBackend struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	OAuthPath   string `json:"oauthPath,omitempty"`
+	OAuthSecret string `json:"oauthSecret,omitempty"`
+	CertSecret  string `json:"certSecret,omitempty"`
+	CertPath    string `json:"certPath,omitempty"`
+	KeyPath     string `json:"keyPath,omitempty"`
+	CAPath      string `json:"caPath,omitempty"`
 }
 
-type DirectorOAuth struct {
-	Data struct {
-		ClientID       string `json:"client_id"`
-		ClientSecret   string `json:"client_secret"`
-		TokensEndpoint string `json:"tokens_endpoint"`
-	} `json:"data"`
+func (b directorBackend) apply(c config) config {
+	override(&c.DirectorURL, b.URL)
+	override(&c.DirectorOAuthPath, b.OAuthPath)
+	override(&c.DirectorOAuthSecret, b.OAuthSecret)
+	override(&c.DirectorCertSecret, b.CertSecret)
+	override(&c.DirectorCertPath, b.CertPath)
+	override(&c.DirectorKeyPath, b.KeyPath)
+	override(&c.DirectorCAPath, b.CAPath)
+	return c
+}
+
+func override(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+func (c *config) String() string {
+	return fmt.Sprintf("Address: %s, APIEndpoint: %s, DirectorURL: %s, SkipDirectorCertVerification: %v, DirectorAuthMode: %s, DirectorOAuthPath: %s",
+		c.Address, c.APIEndpoint, c.DirectorURL,
+		c.SkipDirectorCertVerification, c.DirectorAuthMode, c.DirectorOAuthPath)
 }
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(kyma.AddToScheme(scheme))
 	utilruntime.Must(v1beta1.AddToScheme(scheme))
+	utilruntime.Must(v1beta2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -79,18 +169,22 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var logLevelAddr string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	opts := zap.Options{
-		Development: true,
-	}
-	opts.BindFlags(flag.CommandLine)
+	flag.StringVar(&logLevelAddr, "log-level-bind-address", "127.0.0.1:8082", "The loopback address the log level endpoint binds to. "+
+		"Set it to \"0\" to disable the endpoint.")
+	flag.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "The initial log level: panic, fatal, error, warn, info, debug or trace. "+
+		"It can be changed at runtime on the "+logging.LevelPath+" endpoint.")
+	flag.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "The log format: json or text.")
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	log, err := logging.Setup(logging.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	exitOnError(err, "Failed to set up logging")
+	ctrl.SetLogger(logging.NewLogr(log))
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
@@ -101,16 +195,44 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "2647ec81.kyma-project.io",
 		Cache:                  setCacheOptions(),
+		Controller: ctrlconfig.Controller{
+			ReconciliationTimeout: cfg.ReconciliationTimeout,
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	log := logrus.New()
-	log.SetLevel(logrus.InfoLevel)
+	if logLevelAddr != "0" {
+		levelServer, err := newLogLevelServer(logLevelAddr, log)
+		if err == nil {
+			err = mgr.Add(levelServer)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up log level endpoint")
+			os.Exit(1)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingOTLPEndpoint,
+		Insecure:    cfg.TracingOTLPInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
+	metrics, err := metrics.NewMetrics(metrics.Mode(cfg.MetricsMode))
+	if err != nil {
+		setupLog.Error(err, "unable to create metrics")
+		os.Exit(1)
+	}
 
-	directorClient, err := newDirectorClient(cfg)
+	directorClient, directorRouting, err := newDirectorRouter(cfg, mgr, metrics, log)
 	if err != nil {
 		setupLog.Error(err, "unable to create Director Client")
 		os.Exit(1)
@@ -125,12 +247,11 @@ func main() {
 		runtimeAgentConfigurator = dry
 	} else {
 		compassRegistrator = controllers.NewCompassRegistrator(directorClient, log)
-		runtimeAgentConfigurator = controllers.NewRuntimeAgentConfigurator(directorClient, cfg.ConnectorURLPattern, log)
+		runtimeAgentConfigurator = controllers.NewRuntimeAgentConfigurator(directorClient, cfg.ConnectorURLPattern, log, metrics)
 	}
 
 	requeueTime := time.Second * 5              //nolint:mnd
 	requeueTimeForKubeconfig := time.Minute * 3 //nolint:mnd
-	metrics := metrics.NewMetrics()
 
 	compassManagerReconciler := controllers.NewCompassManagerReconciler(
 		mgr,
@@ -140,13 +261,48 @@ func main() {
 		requeueTime,
 		requeueTimeForKubeconfig,
 		cfg.EnabledRegistration,
+		cfg.AdoptExistingRuntimes,
 		cfg.DryRun,
 		metrics,
+		controllers.NewFailureBackoff(cfg.RetryBaseDelay, cfg.RetryMaxDelay, cfg.RetryMaxAttempts),
+		directorRouting,
+		cfg.MigrationTimeout,
 	)
-	if err = compassManagerReconciler.SetupWithManager(mgr); err != nil {
+	var reconcileSources []source.Source
+	if cfg.RuntimeVerificationPeriod > 0 {
+		verifier := controllers.NewCompassRuntimeVerifier(mgr, log, compassRegistrator, cfg.RuntimeVerificationPeriod, cfg.EnabledRegistration, cfg.DryRun)
+		if err = mgr.Add(verifier); err != nil {
+			setupLog.Error(err, "unable to set up Compass Runtime verification")
+			os.Exit(1)
+		}
+		reconcileSources = append(reconcileSources, verifier.Source())
+	}
+	controllerOptions := controllers.NewControllerOptions(cfg.MaxConcurrentReconciles, cfg.QueueQPS, cfg.QueueBurst)
+	if err = compassManagerReconciler.SetupWithManager(mgr, controllerOptions, reconcileSources...); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CompassManager")
 		os.Exit(1)
 	}
+	if cfg.AgentConfigResyncPeriod > 0 {
+		resyncer := controllers.NewAgentConfigurationResyncer(mgr, log, runtimeAgentConfigurator, cfg.AgentConfigResyncPeriod, cfg.DryRun, metrics)
+		if err = mgr.Add(resyncer); err != nil {
+			setupLog.Error(err, "unable to set up Compass Runtime Agent configuration resync")
+			os.Exit(1)
+		}
+	}
+	if cfg.OrphanGCPeriod > 0 {
+		collector := controllers.NewOrphanedRuntimeCollector(mgr, log, compassRegistrator, directorClient, "kcp-system",
+			cfg.OrphanGCPeriod, cfg.OrphanGCGracePeriod, cfg.OrphanGCDeregister, metrics, directorRouting)
+		if err = mgr.Add(collector); err != nil {
+			setupLog.Error(err, "unable to set up orphaned Runtime collection")
+			os.Exit(1)
+		}
+	}
+	if cfg.EnableConversionWebhook {
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&v1beta2.CompassManagerMapping{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CompassManagerMapping")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -159,28 +315,200 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "unable to flush traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
-func newDirectorClient(config config) (director.Client, error) {
-	file, err := os.ReadFile(config.DirectorOAuthPath)
+// newDirectorRouter creates the client of every Director backend from the backends file, or the single default backend
+// configured with environment variables if the file is not set
+func newDirectorRouter(config config, mgr manager.Manager, metrics metrics.Metrics, log *logrus.Logger) (*director.Router, controllers.DirectorRouting, error) {
+	if config.DirectorBackendsPath == "" {
+		client, err := newDirectorClient(config, "", mgr, metrics, log)
+		if err != nil {
+			return nil, controllers.DirectorRouting{}, err
+		}
+		router, err := director.NewRouter(defaultDirectorBackend, map[string]director.Client{defaultDirectorBackend: client})
+		return router, controllers.DirectorRouting{DefaultBackend: defaultDirectorBackend}, err
+	}
+
+	file, err := os.ReadFile(config.DirectorBackendsPath)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to open director config")
+		return nil, controllers.DirectorRouting{}, errors.Wrap(err, "Failed to read Director backends file")
+	}
+	backendsConfig := directorBackends{}
+	if err := yaml.UnmarshalStrict(file, &backendsConfig); err != nil {
+		return nil, controllers.DirectorRouting{}, errors.Wrap(err, "Failed to unmarshal Director backends file")
+	}
+
+	clients := make(map[string]director.Client, len(backendsConfig.Backends))
+	for _, backend := range backendsConfig.Backends {
+		if backend.Name == "" {
+			return nil, controllers.DirectorRouting{}, errors.New("Director backend must have a name")
+		}
+		if _, ok := clients[backend.Name]; ok {
+			return nil, controllers.DirectorRouting{}, errors.Errorf("Director backend %s is configured more than once", backend.Name)
+		}
+		client, err := newDirectorClient(backend.apply(config), backend.Name, mgr, metrics, log)
+		if err != nil {
+			return nil, controllers.DirectorRouting{}, errors.Wrapf(err, "Failed to create client of Director backend %s", backend.Name)
+		}
+		clients[backend.Name] = client
 	}
 
-	cfg := DirectorOAuth{}
-	err = yaml.Unmarshal(file, &cfg)
+	router, err := director.NewRouter(backendsConfig.Routing.DefaultBackend, clients)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to unmarshal director config")
+		return nil, controllers.DirectorRouting{}, err
 	}
+	for _, rules := range []map[string]string{backendsConfig.Routing.GlobalAccounts, backendsConfig.Routing.Regions} {
+		for key, backend := range rules {
+			if !router.HasBackend(backend) {
+				return nil, controllers.DirectorRouting{}, errors.Errorf("Director backend %s routed for %s is not configured", backend, key)
+			}
+		}
+	}
+
+	log.Infof("Configured Director backends %v, default backend: %s", router.Backends(), router.DefaultBackend())
+	return router, backendsConfig.Routing, nil
+}
 
-	gqlClient := graphql.NewGraphQLClient(config.DirectorURL, true, config.SkipDirectorCertVerification)
-	oauthClient := oauth.NewOauthClient(newHTTPClient(config.SkipDirectorCertVerification), cfg.Data.ClientID, cfg.Data.ClientSecret, cfg.Data.TokensEndpoint)
+// newDirectorClient creates the client of the Director backend, which records the latency and errors of Director operations in the metrics
+func newDirectorClient(config config, backend string, mgr manager.Manager, metrics metrics.Metrics, log *logrus.Logger) (director.Client, error) {
+	rateLimiter := director.NewRateLimiter(config.DirectorQPS, config.DirectorBurst, config.DirectorGlobalAccountQPS, config.DirectorGlobalAccountBurst)
 
-	return director.NewDirectorClient(gqlClient, oauthClient), nil
+	switch config.DirectorAuthMode {
+	case directorAuthModeOAuth:
+		// with tls_client_auth the token is always bound to the client certificate, which must be then presented to Director as well
+		if !config.DirectorOAuthCertBound && oauth.AuthStyle(config.DirectorOAuthAuthStyle) != oauth.AuthStyleTLSClientAuth {
+			tokenSource, err := newDirectorTokenSource(config, backend, newHTTPClient(config.SkipDirectorCertVerification), mgr, metrics, log)
+			if err != nil {
+				return nil, err
+			}
+			gqlClient := graphql.NewGraphQLClient(config.DirectorURL, config.LogGraphQLRequests, config.SkipDirectorCertVerification)
+			return director.NewObservedClient(director.NewDirectorClient(gqlClient, tokenSource, rateLimiter), metrics), nil
+		}
+
+		transport, err := newDirectorCertificateTransport(config, mgr, log)
+		if err != nil {
+			return nil, err
+		}
+		tokenSource, err := newDirectorTokenSource(config, backend, &http.Client{Transport: transport, Timeout: directorHTTPTimeout}, mgr, metrics, log)
+		if err != nil {
+			return nil, err
+		}
+		gqlClient := graphql.NewGraphQLClientWithHTTPClient(config.DirectorURL, config.LogGraphQLRequests, &http.Client{Transport: transport, Timeout: directorHTTPTimeout})
+		return director.NewObservedClient(director.NewDirectorClient(gqlClient, tokenSource, rateLimiter), metrics), nil
+	case directorAuthModeCertificate:
+		transport, err := newDirectorCertificateTransport(config, mgr, log)
+		if err != nil {
+			return nil, err
+		}
+		gqlClient := graphql.NewGraphQLClientWithHTTPClient(config.DirectorURL, config.LogGraphQLRequests, &http.Client{Transport: transport, Timeout: directorHTTPTimeout})
+		return director.NewObservedClient(director.NewDirectorClient(gqlClient, nil, rateLimiter), metrics), nil
+	default:
+		return nil, errors.Errorf("Unknown Director authentication mode %q, expected %q or %q", config.DirectorAuthMode, directorAuthModeOAuth, directorAuthModeCertificate)
+	}
+}
+
+// newDirectorTokenSource watches the OAuth credentials in the secret, or in the file if the secret is not set,
+// so that the rotated credentials are used without a restart. The readiness check fails while the credentials are invalid.
+func newDirectorTokenSource(config config, backend string, httpClient *http.Client, mgr manager.Manager, metrics metrics.Metrics, log *logrus.Logger) (oauth.TokenSource, error) {
+	options, err := newDirectorOAuthOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	oauthClient := oauth.NewObservedClient(oauth.NewOauthClientWithOptions(httpClient, options, "", "", ""), metrics)
+
+	var watcher *oauth.CredentialsWatcher
+	if config.DirectorOAuthSecret != "" {
+		namespace, name, ok := strings.Cut(config.DirectorOAuthSecret, "/")
+		if !ok {
+			return nil, errors.Errorf("Director OAuth credentials secret %q must have the namespace/name format", config.DirectorOAuthSecret)
+		}
+		// secrets are cached, and Compass Manager is allowed to read them, only in the kcp-system namespace
+		if namespace != "kcp-system" {
+			return nil, errors.Errorf("Director OAuth credentials secret %q must be in the kcp-system namespace", config.DirectorOAuthSecret)
+		}
+		watcher = oauth.NewSecretCredentialsWatcher(mgr.GetCache(), types.NamespacedName{Namespace: namespace, Name: name}, config.DirectorOAuthSecretKey, oauthClient, log)
+	} else {
+		watcher = oauth.NewFileCredentialsWatcher(config.DirectorOAuthPath, oauthClient, log)
+	}
+
+	if err := mgr.Add(watcher); err != nil {
+		return nil, errors.Wrap(err, "Failed to set up Director OAuth credentials watch")
+	}
+	checkName := "director-credentials"
+	if backend != "" {
+		checkName += "-" + backend
+	}
+	if err := mgr.AddReadyzCheck(checkName, watcher.Check); err != nil {
+		return nil, errors.Wrap(err, "Failed to set up Director OAuth credentials ready check")
+	}
+	return oauth.NewTokenSource(oauthClient, config.DirectorTokenRefreshSkew), nil
+}
+
+func newDirectorOAuthOptions(config config) (oauth.Options, error) {
+	options := oauth.Options{
+		Scopes:    strings.Fields(config.DirectorOAuthScopes),
+		AuthStyle: oauth.AuthStyle(config.DirectorOAuthAuthStyle),
+		KeyID:     config.DirectorOAuthKeyID,
+	}
+
+	switch options.AuthStyle {
+	case oauth.AuthStyleClientSecretBasic, oauth.AuthStyleClientSecretPost, oauth.AuthStyleTLSClientAuth:
+		return options, nil
+	case oauth.AuthStylePrivateKeyJWT:
+		file, err := os.ReadFile(config.DirectorOAuthPrivateKeyPath)
+		if err != nil {
+			return oauth.Options{}, errors.Wrap(err, "Failed to read Director OAuth private key")
+		}
+		if options.PrivateKey, err = oauth.ParsePrivateKey(file); err != nil {
+			return oauth.Options{}, errors.Wrap(err, "Failed to parse Director OAuth private key")
+		}
+		return options, nil
+	default:
+		return oauth.Options{}, errors.Errorf("Unknown Director OAuth auth style %q", config.DirectorOAuthAuthStyle)
+	}
+}
+
+// newDirectorCertificateTransport loads the client certificate from the secret, or from files if the secret is not set,
+// and reloads it periodically so that the rotated certificate is used without a restart
+func newDirectorCertificateTransport(config config, mgr manager.Manager, log *logrus.Logger) (*mtls.Transport, error) {
+	// the client certificate is configured only to authenticate with Director, it's not sent to a server which isn't verified
+	if config.SkipDirectorCertVerification {
+		return nil, errors.New("SkipDirectorCertVerification is not supported with the Director client certificate")
+	}
+
+	var source mtls.Source
+	if config.DirectorCertSecret != "" {
+		namespace, name, ok := strings.Cut(config.DirectorCertSecret, "/")
+		if !ok {
+			return nil, errors.Errorf("Director client certificate secret %q must have the namespace/name format", config.DirectorCertSecret)
+		}
+		// Compass Manager is allowed to read secrets only in the kcp-system namespace
+		if namespace != "kcp-system" {
+			return nil, errors.Errorf("Director client certificate secret %q must be in the kcp-system namespace", config.DirectorCertSecret)
+		}
+		source = mtls.NewSecretSource(mgr.GetAPIReader(), types.NamespacedName{Namespace: namespace, Name: name})
+	} else {
+		source = mtls.NewFileSource(config.DirectorCertPath, config.DirectorKeyPath, config.DirectorCAPath)
+	}
+
+	transport := mtls.NewTransport(source, config.DirectorCertReloadPeriod, log)
+	if err := transport.Reload(context.Background()); err != nil {
+		return nil, errors.Wrap(err, "Failed to load Director client certificate")
+	}
+	if config.DirectorCertReloadPeriod > 0 {
+		if err := mgr.Add(transport); err != nil {
+			return nil, errors.Wrap(err, "Failed to set up Director client certificate reload")
+		}
+	}
+	return transport, nil
 }
 
 func newHTTPClient(skipCertVerification bool) *http.Client {
@@ -188,7 +516,7 @@ func newHTTPClient(skipCertVerification bool) *http.Client {
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipCertVerification},
 		},
-		Timeout: 30 * time.Second, //nolint:mnd
+		Timeout: directorHTTPTimeout,
 	}
 }
 
@@ -199,6 +527,29 @@ func exitOnError(err error, context string) {
 	}
 }
 
+// newLogLevelServer serves the log level endpoint, which changes the level without authentication, on the loopback address only,
+// so that it's reachable only from the Pod, e.g. with kubectl port-forward
+func newLogLevelServer(addr string, log *logrus.Logger) (*manager.Server, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid log level endpoint address %q", addr)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, errors.Errorf("log level endpoint address %q must be a loopback address", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(logging.LevelPath, logging.LevelHandler(log))
+	return &manager.Server{
+		Name: "log level",
+		Server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second, //nolint:mnd
+		},
+	}, nil
+}
+
 func setCacheOptions() cache.Options {
 	return cache.Options{
 		ByObject: map[client.Object]cache.ByObject{
@@ -213,7 +564,7 @@ func setCacheOptions() cache.Options {
 					"kcp-system": {},
 				},
 			},
-			&v1beta1.CompassManagerMapping{}: {
+			&v1beta2.CompassManagerMapping{}: {
 				Namespaces: map[string]cache.Config{
 					"kcp-system": {},
 				},
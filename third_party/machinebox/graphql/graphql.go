@@ -147,7 +147,7 @@ func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}
 	c.logf("<< %s", buf.String())
 	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
 		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
+			return StatusCodeError{StatusCode: res.StatusCode}
 		}
 		return errors.Wrap(err, "decoding response")
 	}
@@ -223,7 +223,7 @@ func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp inter
 	c.logf("<< %s", buf.String())
 	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
 		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
+			return StatusCodeError{StatusCode: res.StatusCode}
 		}
 		return errors.Wrap(err, "decoding response")
 	}
@@ -263,6 +263,15 @@ func ImmediatelyCloseReqBody() ClientOption {
 // modify the behaviour of the Client.
 type ClientOption func(*Client)
 
+// StatusCodeError is returned when the server responded with a status other than 200 OK and without a GraphQL response
+type StatusCodeError struct {
+	StatusCode int
+}
+
+func (e StatusCodeError) Error() string {
+	return fmt.Sprintf("graphql: server returned a non-200 status code: %v", e.StatusCode)
+}
+
 type ExtendedError interface {
 	Error() string
 	Extensions() map[string]interface{}